@@ -0,0 +1,385 @@
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// DiffReport compares the stored results and the permission maps of two runs
+type DiffReport struct {
+	RunA        string
+	RunB        string
+	Calls       []CallDiff       // api calls whose resources differ
+	Permissions []PermissionFlip // actions allowed in a region in one run and denied in the other
+}
+
+// CallDiff lists the resources of an api call added, removed or changed between the runs.
+// Resources are identified by their ARN, ID or name, the items of a dependent api call by their input as well.
+// Items of a list sharing an identifier are numbered in the order of the list: "Rules/allow#2".
+type CallDiff struct {
+	Service string
+	Region  string
+	ApiCall string
+	Missing string           `json:",omitempty"` // the run without a stored result ( the call failed or wasn't enumerated ), no resource is compared
+	Added   []string         `json:",omitempty"`
+	Removed []string         `json:",omitempty"`
+	Changed []ResourceChange `json:",omitempty"`
+}
+
+// ResourceChange names the fields of a resource whose value changed
+type ResourceChange struct {
+	Resource string
+	Fields   []string
+}
+
+// PermissionFlip is an action that went from allowed to denied ( or back ) in a region,
+// or that was allowed or denied in one run and not tested in the other
+type PermissionFlip struct {
+	Action string
+	Region string
+	Before string
+	After  string
+}
+
+// PERMISSION_UNTESTED is the status of an action missing from the permission map of a run in a region
+const PERMISSION_UNTESTED = "untested"
+
+// Fields that change without any change of the resource, they are not compared
+var volatile_fields = []string{"ResultMetadata", "PasswordLastUsed", "RoleLastUsed", "LastUsedDate", "LastAccessedDate", "LastAuthenticated"}
+
+// Fields identifying the items of a list, by preference. <Kind> is the singular of the list field ( Functions -> Function ).
+var identity_fields = []string{"Arn", "ARN", "arn", "<Kind>Arn", "<Kind>ARN", "<Kind>Id", "Id", "ID", "id", "<Kind>Name", "Name", "name", "Key"}
+
+// run_results are the stored responses of a run, "region|service|api call" -> response
+type run_results map[string]json.RawMessage
+
+// DiffRuns compares the run in dir_b with the run in dir_a, restricted to the services (all when empty)
+func DiffRuns(dir_a, dir_b string, services []string) (*DiffReport, error) {
+	results_a, permissions_a, err := load_run(dir_a, services)
+	if err != nil {
+		return nil, err
+	}
+	results_b, permissions_b, err := load_run(dir_b, services)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DiffReport{RunA: dir_a, RunB: dir_b}
+	keys := make(map[string]bool)
+	for key := range results_a {
+		keys[key] = true
+	}
+	for key := range results_b {
+		keys[key] = true
+	}
+	for _, key := range sorted_keys(keys) {
+		parts := strings.SplitN(key, "|", 3)
+		call := CallDiff{Region: parts[0], Service: parts[1], ApiCall: parts[2]}
+		body_a, in_a := results_a[key]
+		body_b, in_b := results_b[key]
+		switch {
+		case !in_a:
+			call.Missing = dir_a
+		case !in_b:
+			call.Missing = dir_b
+		default:
+			call.compare(resources_of(body_a), resources_of(body_b))
+			if len(call.Added)+len(call.Removed)+len(call.Changed) == 0 {
+				continue
+			}
+		}
+		report.Calls = append(report.Calls, call)
+	}
+
+	report.Permissions = permission_flips(permissions_a, permissions_b, services)
+	return report, nil
+}
+
+// load_run reads the stored results and the permission map of the run in dir
+func load_run(dir string, services []string) (run_results, []servicemaster.Permission, error) {
+	current := utils.FILEPATH
+	utils.SetOutputDir(dir)
+	defer utils.SetOutputDir(current)
+
+	regions := servicemaster.StoredRegions()
+	if len(regions) == 0 {
+		return nil, nil, fmt.Errorf("no results found in %s", dir)
+	}
+	results := make(run_results)
+	for _, region := range regions {
+		for _, svc := range servicemaster.StoredServices(region, false) {
+			if len(services) > 0 && !utils.Find(services, "all") && !utils.Find(services, svc) {
+				continue
+			}
+			stored, err := servicemaster.LoadResults(region, svc, false)
+			if err != nil {
+				continue
+			}
+			for apicall, body := range stored {
+				results[region+"|"+svc+"|"+apicall] = body
+			}
+		}
+	}
+
+	// runs enumerated before the permission map existed are compared without it
+	permissions, _ := servicemaster.LoadPermissions()
+	return results, permissions, nil
+}
+
+// compare fills the resources added, removed and changed from a to b
+func (call *CallDiff) compare(a, b map[string]interface{}) {
+	for key, value_b := range b {
+		value_a, ok := a[key]
+		if !ok {
+			call.Added = append(call.Added, key)
+			continue
+		}
+		if fields := changed_fields(value_a, value_b); len(fields) > 0 {
+			call.Changed = append(call.Changed, ResourceChange{Resource: key, Fields: fields})
+		}
+	}
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			call.Removed = append(call.Removed, key)
+		}
+	}
+	sort.Strings(call.Added)
+	sort.Strings(call.Removed)
+	sort.Slice(call.Changed, func(i, j int) bool { return call.Changed[i].Resource < call.Changed[j].Resource })
+}
+
+// resources_of splits a stored response into its resources: the items of its lists and the remaining fields.
+// The records of a dependent api call are prefixed with their input, failed records are left out.
+func resources_of(body json.RawMessage) map[string]interface{} {
+	var value interface{}
+	if json.Unmarshal(body, &value) != nil {
+		return nil
+	}
+	resources := make(map[string]interface{})
+
+	if records, ok := value.([]interface{}); ok {
+		for _, record := range records {
+			record, ok := record.(map[string]interface{})
+			if !ok || record["Output"] == nil {
+				continue
+			}
+			collect_resources(resources, input_label(record["Input"])+" ", record["Output"])
+		}
+		return resources
+	}
+	collect_resources(resources, "", value)
+	return resources
+}
+
+// collect_resources adds the items of the list fields of the response, the other fields form the resource "prefix(response)"
+func collect_resources(resources map[string]interface{}, prefix string, value interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		resources[prefix+"(response)"] = value
+		return
+	}
+
+	attributes := make(map[string]interface{})
+	for field, field_value := range object {
+		if utils.Find(volatile_fields, field) {
+			continue
+		}
+		items, ok := field_value.([]interface{})
+		if !ok {
+			if field_value != nil {
+				attributes[field] = field_value
+			}
+			continue
+		}
+		for _, item := range items {
+			resources[unique_key(resources, prefix+field+"/"+item_id(field, item))] = item
+		}
+	}
+	if len(attributes) > 0 {
+		resources[prefix+"(response)"] = attributes
+	}
+}
+
+// unique_key numbers the key when an item with the same identifier is already collected
+func unique_key(resources map[string]interface{}, key string) string {
+	if _, taken := resources[key]; !taken {
+		return key
+	}
+	for n := 2; ; n++ {
+		numbered := fmt.Sprintf("%s#%d", key, n)
+		if _, taken := resources[numbered]; !taken {
+			return numbered
+		}
+	}
+}
+
+// item_id returns the ARN, ID or name of a list item, the item itself when it is a plain value
+func item_id(field string, item interface{}) string {
+	if value, ok := item.(string); ok {
+		return value
+	}
+	object, ok := item.(map[string]interface{})
+	if !ok {
+		return compact(item)
+	}
+
+	kind := singular(field)
+	for _, name := range identity_fields {
+		name = strings.Replace(name, "<Kind>", kind, 1)
+		if id, ok := object[name].(string); ok && id != "" {
+			return id
+		}
+	}
+	// any other identifying field, ARNs first
+	for _, suffix := range []string{"Arn", "ARN", "Id", "Name"} {
+		for _, name := range sorted_fields(object) {
+			if id, ok := object[name].(string); ok && id != "" && strings.HasSuffix(name, suffix) {
+				return id
+			}
+		}
+	}
+	return compact(item)
+}
+
+// singular returns the kind of the items of a list field: Functions -> Function, Policies -> Policy, Addresses -> Address, UserDetailList -> UserDetail
+func singular(field string) string {
+	switch {
+	case strings.HasSuffix(field, "List"):
+		return strings.TrimSuffix(field, "List")
+	case strings.HasSuffix(field, "ies"):
+		return strings.TrimSuffix(field, "ies") + "y"
+	case strings.HasSuffix(field, "sses"), strings.HasSuffix(field, "xes"), strings.HasSuffix(field, "ches"), strings.HasSuffix(field, "shes"):
+		return strings.TrimSuffix(field, "es")
+	}
+	return strings.TrimSuffix(field, "s")
+}
+
+// input_label names the record of a dependent api call by the fields of its input: "FunctionName=app"
+func input_label(input interface{}) string {
+	object, ok := input.(map[string]interface{})
+	if !ok {
+		return compact(input)
+	}
+	var parts []string
+	for _, field := range sorted_fields(object) {
+		if object[field] == nil {
+			continue
+		}
+		value, ok := object[field].(string)
+		if !ok {
+			value = compact(object[field])
+		}
+		parts = append(parts, field+"="+value)
+	}
+	return strings.Join(parts, ",")
+}
+
+// changed_fields returns the top level fields whose value differs, "." when plain values differ
+func changed_fields(a, b interface{}) []string {
+	object_a, ok_a := a.(map[string]interface{})
+	object_b, ok_b := b.(map[string]interface{})
+	if !ok_a || !ok_b {
+		if compact(a) != compact(b) {
+			return []string{"."}
+		}
+		return nil
+	}
+
+	var fields []string
+	for _, field := range sorted_fields(object_b) {
+		if !utils.Find(volatile_fields, field) && compact(object_a[field]) != compact(object_b[field]) {
+			fields = append(fields, field)
+		}
+	}
+	for _, field := range sorted_fields(object_a) {
+		if _, ok := object_b[field]; !ok && !utils.Find(volatile_fields, field) && object_a[field] != nil {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// permission_flips lists the regions where an action of the services was allowed in a and denied in b, or the opposite,
+// and the actions allowed or denied in a region in one run only. Inconclusive statuses are not compared.
+func permission_flips(a, b []servicemaster.Permission, services []string) []PermissionFlip {
+	var prefixes []string
+	if len(services) > 0 && !utils.Find(services, "all") {
+		for _, svc := range services {
+			prefixes = append(prefixes, strings.SplitN(servicemaster.IAMAction(svc, ""), ":", 2)[0]+":")
+		}
+	}
+
+	before, after := permission_statuses(a, prefixes), permission_statuses(b, prefixes)
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	var flips []PermissionFlip
+	for key := range keys {
+		previous, ok := before[key]
+		if !ok {
+			previous = PERMISSION_UNTESTED
+		}
+		status, ok := after[key]
+		if !ok {
+			status = PERMISSION_UNTESTED
+		}
+		if previous == status || previous == servicemaster.PERMISSION_INCONCLUSIVE || status == servicemaster.PERMISSION_INCONCLUSIVE {
+			continue
+		}
+		parts := strings.SplitN(key, "|", 2)
+		flips = append(flips, PermissionFlip{Action: parts[0], Region: parts[1], Before: previous, After: status})
+	}
+	sort.Slice(flips, func(i, j int) bool {
+		if flips[i].Action != flips[j].Action {
+			return flips[i].Action < flips[j].Action
+		}
+		return flips[i].Region < flips[j].Region
+	})
+	return flips
+}
+
+// permission_statuses maps "action|region" to the status of the action in the region
+func permission_statuses(permissions []servicemaster.Permission, prefixes []string) map[string]string {
+	statuses := make(map[string]string)
+	for _, permission := range permissions {
+		if prefixes != nil && !has_prefix(permission.Action, prefixes) {
+			continue
+		}
+		for status, regions := range map[string][]string{
+			servicemaster.PERMISSION_ALLOWED:      permission.Allowed,
+			servicemaster.PERMISSION_DENIED:       permission.Denied,
+			servicemaster.PERMISSION_INCONCLUSIVE: permission.Inconclusive,
+		} {
+			for _, region := range regions {
+				statuses[permission.Action+"|"+region] = status
+			}
+		}
+	}
+	return statuses
+}
+
+func has_prefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compact is the canonical JSON of a value, object keys are sorted by encoding/json
+func compact(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -0,0 +1,155 @@
+package analyze
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// store_run writes the stored responses (region -> service -> api call -> response) and the permission map of a run
+func store_run(t *testing.T, results map[string]map[string]map[string]string, permissions []servicemaster.Permission) string {
+	t.Helper()
+	dir := use_run_directory(t)
+	for region, services := range results {
+		for svc, responses := range services {
+			store_results(t, region, svc, responses)
+		}
+	}
+	data, _ := json.Marshal(permissions)
+	if err := os.WriteFile(filepath.Join(dir, servicemaster.PERMISSIONS_FILE), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDiffRuns(t *testing.T) {
+	run_a := store_run(t, map[string]map[string]map[string]string{
+		"eu-west-1": {
+			"lambda": {
+				"ListFunctions": `{"Functions": [{"FunctionName": "app", "FunctionArn": "arn:app", "Runtime": "python3.9"}, {"FunctionName": "old", "FunctionArn": "arn:old"}], "ResultMetadata": {"RequestId": "1"}}`,
+				"GetPolicy":     `[{"Input": {"FunctionName": "app"}, "Output": {"Policy": "v1"}}, {"Input": {"FunctionName": "gone"}, "Error": {"Class": "not_found"}}]`,
+			},
+			"test": {"ListRules": `{"Rules": [{"Name": "allow", "Port": 22}, {"Name": "allow", "Port": 443}]}`},
+		},
+		utils.GLOBAL_REGION: {
+			"iam": {"ListUsers": `{"Users": [{"UserName": "dev", "Arn": "arn:user/dev", "PasswordLastUsed": "2024-01-01T00:00:00Z"}]}`},
+			"s3":  {"ListBuckets": `{"Buckets": [{"Name": "logs"}]}`},
+		},
+	}, []servicemaster.Permission{
+		{Action: "lambda:ListFunctions", Allowed: []string{"eu-west-1", "us-east-1"}},
+		{Action: "lambda:GetPolicy", Inconclusive: []string{"eu-west-1"}},
+		{Action: "iam:ListUsers", Allowed: []string{utils.GLOBAL_REGION}},
+		{Action: "s3:ListBuckets", Allowed: []string{utils.GLOBAL_REGION}},
+	})
+	run_b := store_run(t, map[string]map[string]map[string]string{
+		"eu-west-1": {
+			"lambda": {
+				"ListFunctions": `{"Functions": [{"FunctionName": "app", "FunctionArn": "arn:app", "Runtime": "python3.12"}, {"FunctionName": "new", "FunctionArn": "arn:new"}], "ResultMetadata": {"RequestId": "2"}}`,
+				"GetPolicy":     `[{"Input": {"FunctionName": "app"}, "Output": {"Policy": "v2"}}]`,
+			},
+			"test": {"ListRules": `{"Rules": [{"Name": "allow", "Port": 22}]}`},
+		},
+		utils.GLOBAL_REGION: {
+			"iam": {"ListUsers": `{"Users": [{"UserName": "dev", "Arn": "arn:user/dev", "PasswordLastUsed": "2024-02-01T00:00:00Z"}]}`},
+		},
+	}, []servicemaster.Permission{
+		{Action: "lambda:ListFunctions", Allowed: []string{"eu-west-1"}, Denied: []string{"us-east-1"}},
+		{Action: "lambda:GetPolicy", Allowed: []string{"eu-west-1"}},
+		{Action: "iam:ListUsers", Allowed: []string{utils.GLOBAL_REGION}},
+		{Action: "ec2:DescribeInstances", Denied: []string{"eu-west-1"}},
+	})
+
+	report, err := DiffRuns(run_a, run_b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want_calls := []CallDiff{
+		{Service: "lambda", Region: "eu-west-1", ApiCall: "GetPolicy", Changed: []ResourceChange{{Resource: "FunctionName=app (response)", Fields: []string{"Policy"}}}},
+		{Service: "lambda", Region: "eu-west-1", ApiCall: "ListFunctions", Added: []string{"Functions/arn:new"}, Removed: []string{"Functions/arn:old"}, Changed: []ResourceChange{{Resource: "Functions/arn:app", Fields: []string{"Runtime"}}}},
+		// two rules named allow: the second one is removed, the first one is unchanged
+		{Service: "test", Region: "eu-west-1", ApiCall: "ListRules", Removed: []string{"Rules/allow#2"}},
+		{Service: "s3", Region: utils.GLOBAL_REGION, ApiCall: "ListBuckets", Missing: run_b},
+	}
+	if !reflect.DeepEqual(report.Calls, want_calls) {
+		t.Errorf("calls\n%+v\nwant\n%+v", report.Calls, want_calls)
+	}
+	want_flips := []PermissionFlip{
+		{Action: "ec2:DescribeInstances", Region: "eu-west-1", Before: PERMISSION_UNTESTED, After: servicemaster.PERMISSION_DENIED},
+		{Action: "lambda:ListFunctions", Region: "us-east-1", Before: servicemaster.PERMISSION_ALLOWED, After: servicemaster.PERMISSION_DENIED},
+		{Action: "s3:ListBuckets", Region: utils.GLOBAL_REGION, Before: servicemaster.PERMISSION_ALLOWED, After: PERMISSION_UNTESTED},
+	}
+	if !reflect.DeepEqual(report.Permissions, want_flips) {
+		t.Errorf("permissions\n%+v\nwant\n%+v", report.Permissions, want_flips)
+	}
+
+	// restricted to a service, the other calls and actions are left out
+	report, _ = DiffRuns(run_a, run_b, []string{"s3"})
+	if len(report.Calls) != 1 || report.Calls[0].Service != "s3" || len(report.Permissions) != 1 || report.Permissions[0].Action != "s3:ListBuckets" {
+		t.Errorf("s3 only: %+v %+v", report.Calls, report.Permissions)
+	}
+
+	if _, err := DiffRuns(run_a, t.TempDir(), nil); err == nil {
+		t.Error("a run without results is compared")
+	}
+}
+
+func TestResourcesOf(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		resources []string
+	}{
+		{"list items and attributes", `{"Users": [{"UserId": "AID1"}, {"Arn": "arn:user/b", "UserId": "AID2"}], "IsTruncated": false, "Marker": null}`, []string{"(response)", "Users/AID1", "Users/arn:user/b"}},
+		{"plain values", `{"TableNames": ["orders", "users"]}`, []string{"TableNames/orders", "TableNames/users"}},
+		{"shared identifiers", `{"Rules": [{"Name": "a", "Port": 1}, {"Name": "a", "Port": 2}, {"Name": "a", "Port": 3}]}`, []string{"Rules/a", "Rules/a#2", "Rules/a#3"}},
+		{"item without identifier", `{"Pairs": [{"Left": 1}]}`, []string{`Pairs/{"Left":1}`}},
+		{"volatile fields only", `{"ResultMetadata": {}}`, nil},
+		{"dependent records", `[{"Input": {"Bucket": "logs", "ExpectedBucketOwner": null}, "Output": {"Rules": [{"ID": "expire"}]}}, {"Input": {"Bucket": "data"}, "Error": {"Class": "not_found"}}]`, []string{"Bucket=logs Rules/expire"}},
+		{"dependent records sharing items", `[{"Input": {"Cluster": "a"}, "Output": {"Services": ["web"]}}, {"Input": {"Cluster": "b"}, "Output": {"Services": ["web"]}}]`, []string{"Cluster=a Services/web", "Cluster=b Services/web"}},
+		{"plain response", `"text"`, []string{"(response)"}},
+	}
+	for _, test := range tests {
+		var keys []string
+		for key := range resources_of(json.RawMessage(test.body)) {
+			keys = append(keys, key)
+		}
+		if got := sorted_keys(set_of(keys)); !reflect.DeepEqual(got, test.resources) && !(len(got) == 0 && len(test.resources) == 0) {
+			t.Errorf("%s: resources %q, want %q", test.name, got, test.resources)
+		}
+	}
+}
+
+func set_of(values []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+func TestChangedFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   string
+		fields []string
+	}{
+		{"same", `{"Name": "a", "Tags": [{"Key": "k"}]}`, `{"Tags": [{"Key": "k"}], "Name": "a"}`, nil},
+		{"changed, added and removed", `{"Name": "a", "State": "on", "Old": 1}`, `{"Name": "b", "State": "on", "New": 2}`, []string{"Name", "New", "Old"}},
+		{"volatile fields", `{"RoleLastUsed": {"LastUsedDate": "1"}}`, `{"RoleLastUsed": {"LastUsedDate": "2"}}`, nil},
+		{"removed null field", `{"Name": "a", "Description": null}`, `{"Name": "a"}`, nil},
+		{"plain values", `"a"`, `"b"`, []string{"."}},
+	}
+	for _, test := range tests {
+		var a, b interface{}
+		json.Unmarshal([]byte(test.a), &a)
+		json.Unmarshal([]byte(test.b), &b)
+		if got := changed_fields(a, b); !reflect.DeepEqual(got, test.fields) {
+			t.Errorf("%s: fields %v, want %v", test.name, got, test.fields)
+		}
+	}
+}
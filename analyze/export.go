@@ -0,0 +1,168 @@
+package analyze
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+)
+
+// Export formats of the findings
+const (
+	FORMAT_JSON  = "json"
+	FORMAT_CSV   = "csv"
+	FORMAT_SARIF = "sarif"
+)
+
+// WriteFindings writes the findings in the format (json, csv or sarif)
+func WriteFindings(w io.Writer, format string, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	switch format {
+	case FORMAT_JSON:
+		return write_json(w, findings)
+	case FORMAT_CSV:
+		return write_csv(w, findings)
+	case FORMAT_SARIF:
+		return write_json(w, sarif_log(findings))
+	default:
+		return fmt.Errorf("unknown format %q, use %s, %s or %s", format, FORMAT_JSON, FORMAT_CSV, FORMAT_SARIF)
+	}
+}
+
+func write_json(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func write_csv(w io.Writer, findings []Finding) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"ID", "Title", "Severity", "Resource", "Service", "Region", "ApiCall", "Evidence", "Description", "Remediation"})
+	for _, finding := range findings {
+		writer.Write([]string{finding.ID, finding.Title, finding.Severity, finding.Resource, finding.Service, finding.Region, finding.ApiCall, finding.Evidence, finding.Description, finding.Remediation})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// SARIF 2.1.0, the subset read by code scanning tools and trackers
+type sarif_report struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []sarif_run `json:"runs"`
+}
+
+type sarif_run struct {
+	Tool struct {
+		Driver struct {
+			Name           string       `json:"name"`
+			InformationURI string       `json:"informationUri"`
+			Rules          []sarif_rule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarif_result `json:"results"`
+}
+
+type sarif_text struct {
+	Text string `json:"text"`
+}
+
+type sarif_rule struct {
+	ID                   string     `json:"id"`
+	ShortDescription     sarif_text `json:"shortDescription"`
+	Help                 sarif_text `json:"help"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+	Properties map[string]string `json:"properties"`
+}
+
+type sarif_result struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarif_text        `json:"message"`
+	Locations           []sarif_location  `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]string `json:"properties"`
+}
+
+type sarif_location struct {
+	PhysicalLocation *sarif_physical_location `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarif_logical_location `json:"logicalLocations,omitempty"`
+}
+
+type sarif_physical_location struct {
+	ArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId"`
+	} `json:"artifactLocation"`
+}
+
+type sarif_logical_location struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// SARIF levels and security-severity scores (the scale of GitHub code scanning) of the severities
+var sarif_levels = map[string]string{SEVERITY_CRITICAL: "error", SEVERITY_HIGH: "error", SEVERITY_MEDIUM: "warning", SEVERITY_LOW: "note"}
+var sarif_scores = map[string]string{SEVERITY_CRITICAL: "9.5", SEVERITY_HIGH: "8.0", SEVERITY_MEDIUM: "5.5", SEVERITY_LOW: "3.0"}
+
+// sarif_log builds a SARIF log with a rule per finding ID. The evidence is located in the stored results file
+// (relative to the RESULTS base, the output directory) and by the JSON path under the api call, the resource by its ARN.
+func sarif_log(findings []Finding) sarif_report {
+	run := sarif_run{Results: []sarif_result{}}
+	run.Tool.Driver.Name = "aws-enumerator"
+	run.Tool.Driver.InformationURI = "https://github.com/threatroute66/aws-enumerator"
+	run.Tool.Driver.Rules = []sarif_rule{}
+
+	rules := make(map[string]bool)
+	for _, finding := range findings {
+		if !rules[finding.ID] {
+			rules[finding.ID] = true
+			rule := sarif_rule{
+				ID:               finding.ID,
+				ShortDescription: sarif_text{finding.Title},
+				Help:             sarif_text{finding.Remediation},
+				Properties:       map[string]string{"security-severity": sarif_scores[finding.Severity]},
+			}
+			rule.DefaultConfiguration.Level = sarif_levels[finding.Severity]
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		location := sarif_location{}
+		if finding.ApiCall != "" {
+			location.PhysicalLocation = &sarif_physical_location{}
+			location.PhysicalLocation.ArtifactLocation.URI = path.Join(finding.Region, finding.Service+".json")
+			location.PhysicalLocation.ArtifactLocation.URIBaseID = "RESULTS"
+			location.LogicalLocations = append(location.LogicalLocations, sarif_logical_location{
+				Name:               finding.ApiCall,
+				FullyQualifiedName: finding.ApiCall + finding.Evidence,
+				Kind:               "object",
+			})
+		}
+		if finding.Resource != "" {
+			location.LogicalLocations = append(location.LogicalLocations, sarif_logical_location{FullyQualifiedName: finding.Resource, Kind: "resource"})
+		}
+
+		run.Results = append(run.Results, sarif_result{
+			RuleID:    finding.ID,
+			Level:     sarif_levels[finding.Severity],
+			Message:   sarif_text{finding.Description},
+			Locations: []sarif_location{location},
+			PartialFingerprints: map[string]string{
+				"findingKey/v1": finding.ID + "|" + finding.Region + "|" + finding.Resource + "|" + finding.ApiCall + finding.Evidence,
+			},
+			Properties: map[string]string{"severity": finding.Severity, "service": finding.Service, "region": finding.Region, "resource": finding.Resource},
+		})
+	}
+
+	return sarif_report{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarif_run{run},
+	}
+}
@@ -0,0 +1,184 @@
+package analyze
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// export_findings covers every severity, two findings of the same ID and a finding without stored evidence
+var export_findings = []Finding{
+	{ID: "s3-public-bucket", Title: "Public S3 bucket", Severity: SEVERITY_CRITICAL, Resource: "arn:aws:s3:::logs", Service: "s3", Region: "global", ApiCall: "GetBucketAcl", Evidence: "$[0].Output.Grants[1]", Description: "bucket logs is public", Remediation: "Remove the grants"},
+	{ID: "ec2-public-ami", Title: "Public AMI", Severity: SEVERITY_HIGH, Resource: "arn:aws:ec2:eu-west-1::image/ami-1", Service: "ec2", Region: "eu-west-1", ApiCall: "DescribeImages", Evidence: "$.Images[0].Public", Description: "AMI ami-1 is public", Remediation: "Remove the launch permission"},
+	{ID: "s3-public-bucket", Title: "Public S3 bucket", Severity: SEVERITY_MEDIUM, Resource: "arn:aws:s3:::data", Service: "s3", Region: "global", ApiCall: "GetBucketPolicy", Evidence: "$[1].Output.Policy", Description: "bucket data is public, under conditions", Remediation: "Remove the grants"},
+	{ID: "privesc-pivot", Title: "Privilege escalation to another principal", Severity: SEVERITY_LOW, Resource: "arn:aws:iam::111111111111:user/dev", Service: "iam", Region: "global", Description: "dev can reach ops", Remediation: "Remove iam:CreateAccessKey"},
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteFindings(&buffer, FORMAT_SARIF, export_findings); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID                   string            `json:"id"`
+						DefaultConfiguration map[string]string `json:"defaultConfiguration"`
+						Properties           map[string]string `json:"properties"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID              string            `json:"ruleId"`
+				Level               string            `json:"level"`
+				Message             map[string]string `json:"message"`
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+				Locations           []struct {
+					PhysicalLocation *struct {
+						ArtifactLocation map[string]string `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+					LogicalLocations []map[string]string `json:"logicalLocations"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Name != "aws-enumerator" {
+		t.Fatalf("log %s", buffer.String())
+	}
+	run := log.Runs[0]
+
+	// one rule per ID, configured from its first (most severe) finding
+	rules := make(map[string][2]string)
+	for _, rule := range run.Tool.Driver.Rules {
+		if _, ok := rules[rule.ID]; ok {
+			t.Errorf("rule %s listed twice", rule.ID)
+		}
+		rules[rule.ID] = [2]string{rule.DefaultConfiguration["level"], rule.Properties["security-severity"]}
+	}
+	want_rules := map[string][2]string{
+		"s3-public-bucket": {"error", "9.5"},
+		"ec2-public-ami":   {"error", "8.0"},
+		"privesc-pivot":    {"note", "3.0"},
+	}
+	if !reflect.DeepEqual(rules, want_rules) {
+		t.Errorf("rules %v, want %v", rules, want_rules)
+	}
+
+	levels := []string{"error", "error", "warning", "note"}
+	if len(run.Results) != len(export_findings) {
+		t.Fatalf("%d results, want %d", len(run.Results), len(export_findings))
+	}
+	for i, result := range run.Results {
+		finding := export_findings[i]
+		if result.RuleID != finding.ID || result.Level != levels[i] || result.Message["text"] != finding.Description {
+			t.Errorf("result %d: %s %s %q", i, result.RuleID, result.Level, result.Message["text"])
+		}
+		if key := result.PartialFingerprints["findingKey/v1"]; key != finding.ID+"|"+finding.Region+"|"+finding.Resource+"|"+finding.ApiCall+finding.Evidence {
+			t.Errorf("result %d: fingerprint %q", i, key)
+		}
+		if len(result.Locations) != 1 {
+			t.Fatalf("result %d: locations %+v", i, result.Locations)
+		}
+		location := result.Locations[0]
+		last := location.LogicalLocations[len(location.LogicalLocations)-1]
+		if last["kind"] != "resource" || last["fullyQualifiedName"] != finding.Resource {
+			t.Errorf("result %d: resource location %v", i, last)
+		}
+		if finding.ApiCall == "" {
+			if location.PhysicalLocation != nil || len(location.LogicalLocations) != 1 {
+				t.Errorf("result %d: the finding without api call has a stored result location", i)
+			}
+			continue
+		}
+		if location.PhysicalLocation == nil {
+			t.Errorf("result %d: no physical location", i)
+			continue
+		}
+		artifact := location.PhysicalLocation.ArtifactLocation
+		if artifact["uri"] != finding.Region+"/"+finding.Service+".json" || artifact["uriBaseId"] != "RESULTS" {
+			t.Errorf("result %d: artifact %v", i, artifact)
+		}
+		if evidence := location.LogicalLocations[0]; evidence["name"] != finding.ApiCall || evidence["fullyQualifiedName"] != finding.ApiCall+finding.Evidence {
+			t.Errorf("result %d: evidence location %v", i, evidence)
+		}
+	}
+}
+
+func TestWriteSARIFEmpty(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteFindings(&buffer, FORMAT_SARIF, nil); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []interface{} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []interface{} `json:"results"`
+		} `json:"runs"`
+	}
+	json.Unmarshal(buffer.Bytes(), &log)
+	// empty arrays, not null: the SARIF schema requires arrays
+	if len(log.Runs) != 1 || log.Runs[0].Results == nil || log.Runs[0].Tool.Driver.Rules == nil {
+		t.Errorf("log %s", buffer.String())
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	findings := []Finding{
+		{ID: "secret-jwt", Severity: SEVERITY_HIGH, Service: "lambda", Description: "token, in the \"environment\"\nof the function", Remediation: "Rotate it"},
+	}
+	var buffer bytes.Buffer
+	if err := WriteFindings(&buffer, FORMAT_CSV, findings); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"ID", "Title", "Severity", "Resource", "Service", "Region", "ApiCall", "Evidence", "Description", "Remediation"},
+		{"secret-jwt", "", "high", "", "lambda", "", "", "", "token, in the \"environment\"\nof the function", "Rotate it"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records %q, want %q", records, want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteFindings(&buffer, FORMAT_JSON, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buffer.String() != "[]\n" {
+		t.Errorf("no findings written as %q", buffer.String())
+	}
+	buffer.Reset()
+	WriteFindings(&buffer, FORMAT_JSON, export_findings)
+	var findings []Finding
+	if err := json.Unmarshal(buffer.Bytes(), &findings); err != nil || !reflect.DeepEqual(findings, export_findings) {
+		t.Errorf("findings %+v: %v", findings, err)
+	}
+}
+
+func TestWriteFindingsUnknownFormat(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WriteFindings(&buffer, "xml", export_findings)
+	if err == nil || err.Error() != `unknown format "xml", use json, csv or sarif` {
+		t.Errorf("error %v", err)
+	}
+	if buffer.Len() != 0 {
+		t.Errorf("written %q", buffer.String())
+	}
+}
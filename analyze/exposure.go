@@ -0,0 +1,811 @@
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/policy"
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// EXPOSURE_FILE is written to utils.FILEPATH by analyze exposure
+const EXPOSURE_FILE = "analyze-exposure.json"
+
+// ExposureReport is the result of analyze exposure, saved to EXPOSURE_FILE
+type ExposureReport struct {
+	Findings []Finding
+	Missing  []string `json:",omitempty"` // "<service> <api call>" sources not found in the stored results
+}
+
+// Titles and remediations of the exposure checks
+var exposure_kinds = map[string]finding_kind{
+	"ec2-open-security-group":    {"Security group open to the internet", "Restrict the ingress rule to known CIDR ranges, or reach the instances through SSM Session Manager or a VPN"},
+	"ec2-public-ami":             {"Public AMI", "Remove the launch permission of the all group (ec2 modify-image-attribute)"},
+	"ec2-public-snapshot":        {"Public EBS snapshot", "Remove the create volume permission of the all group (ec2 modify-snapshot-attribute)"},
+	"rds-public-instance":        {"Publicly accessible RDS instance", "Disable PubliclyAccessible and restrict the security groups of the instance"},
+	"rds-public-snapshot":        {"Public RDS snapshot", "Remove all from the restore attribute of the snapshot (rds modify-db-snapshot-attribute)"},
+	"s3-public-bucket":           {"Public S3 bucket", "Remove the public statements and grants, and enable S3 Block Public Access"},
+	"lambda-public-function-url": {"Lambda function URL without authentication", "Set the AuthType of the function URL to AWS_IAM, or delete the URL"},
+	"lambda-public-policy":       {"Lambda function invocable by everyone", "Scope the Principal of the function policy to accounts or services, with a SourceArn or SourceAccount condition"},
+	"apigateway-no-authorizer":   {"API Gateway endpoint without authorization", "Attach an authorizer (IAM, Cognito, Lambda, JWT) or require an API key on the methods and routes"},
+	"ecr-public-repository":      {"Public ECR repository", "Scope the Principal of the repository policy to known accounts"},
+	"elb-internet-facing":        {"Internet-facing load balancer", "Check that the load balancer has to be public, use the internal scheme otherwise"},
+}
+
+// Ports worth reporting when open to the internet
+var sensitive_ports = map[int]string{
+	20: "ftp-data", 21: "ftp", 22: "ssh", 23: "telnet", 25: "smtp", 135: "msrpc", 139: "netbios", 445: "smb",
+	1433: "mssql", 1521: "oracle", 2049: "nfs", 2375: "docker", 2376: "docker-tls", 3306: "mysql", 3389: "rdp",
+	5432: "postgresql", 5601: "kibana", 5900: "vnc", 5984: "couchdb", 6379: "redis", 7001: "weblogic",
+	8020: "hdfs", 8080: "http-alt", 8088: "yarn", 9042: "cassandra", 9092: "kafka", 9200: "elasticsearch",
+	9300: "elasticsearch", 10250: "kubelet", 11211: "memcached", 27017: "mongodb",
+}
+
+// Stored api calls the checks read, reported as missing when no region holds them
+var exposure_sources = []struct{ service, apicall string }{
+	{"ec2", "DescribeSecurityGroups"},
+	{"ec2", "DescribeImages"},
+	{"ec2", "DescribeSnapshotAttribute"},
+	{"rds", "DescribeDBInstances"},
+	{"rds", "DescribeDBSnapshotAttributes"},
+	{"rds", "DescribeDBClusterSnapshotAttributes"},
+	{"s3", "GetBucketPolicy"},
+	{"s3", "GetBucketPolicyStatus"},
+	{"s3", "GetBucketAcl"},
+	{"s3", "GetPublicAccessBlock"},
+	{"lambda", "ListFunctionUrlConfigs"},
+	{"lambda", "GetPolicy"},
+	{"apigateway", "GetResources"},
+	{"apigatewayv2", "GetRoutes"},
+	{"ecr", "GetRepositoryPolicy"},
+	{"elb", "DescribeLoadBalancers"},
+	{"elbv2", "DescribeLoadBalancers"},
+}
+
+// open_permission is an ingress rule of a security group open to the internet
+type open_permission struct {
+	from, to int // -1: every port
+	path     string
+}
+
+// exposure_analyzer runs the checks on the stored results of a region at a time
+type exposure_analyzer struct {
+	report  *ExposureReport
+	account string
+	found   map[string]bool
+	open    map[string][]open_permission // security group id -> rules open to the internet
+}
+
+// AnalyzeExposure inspects the stored results of every region for resources exposed to the internet,
+// the exposures are ranked by severity
+func AnalyzeExposure() *ExposureReport {
+	analyzer := &exposure_analyzer{report: &ExposureReport{}, account: stored_account(), found: make(map[string]bool)}
+
+	for _, region := range servicemaster.StoredRegions() {
+		analyzer.open = make(map[string][]open_permission)
+		analyzer.region(region, "ec2", analyzer.ec2)
+		analyzer.region(region, "rds", analyzer.rds)
+		analyzer.region(region, "s3", analyzer.s3)
+		analyzer.region(region, "lambda", analyzer.lambda)
+		analyzer.region(region, "apigateway", analyzer.apigateway)
+		analyzer.region(region, "apigatewayv2", analyzer.apigatewayv2)
+		analyzer.region(region, "ecr", analyzer.ecr)
+		analyzer.region(region, "elb", analyzer.elb)
+		analyzer.region(region, "elbv2", analyzer.elbv2)
+	}
+
+	for _, source := range exposure_sources {
+		if !analyzer.found[source.service+" "+source.apicall] {
+			analyzer.report.Missing = append(analyzer.report.Missing, source.service+" "+source.apicall)
+		}
+	}
+
+	SortFindings(analyzer.report.Findings)
+	return analyzer.report
+}
+
+// region loads the stored results of a service in a region and runs its checks
+func (analyzer *exposure_analyzer) region(region, svc string, check func(region string, results map[string]json.RawMessage)) {
+	results, err := servicemaster.LoadResults(region, svc, false)
+	if err != nil {
+		return
+	}
+	for apicall := range results {
+		analyzer.found[svc+" "+apicall] = true
+	}
+	check(region, results)
+}
+
+func (analyzer *exposure_analyzer) add(finding Finding) {
+	kind := exposure_kinds[finding.ID]
+	finding.Title, finding.Remediation = kind.title, kind.remediation
+	analyzer.report.Findings = append(analyzer.report.Findings, finding)
+}
+
+func (analyzer *exposure_analyzer) ec2(region string, results map[string]json.RawMessage) {
+	var groups struct {
+		SecurityGroups []struct {
+			GroupId       string
+			GroupName     string
+			OwnerId       string
+			IpPermissions []struct {
+				IpProtocol string
+				FromPort   *int
+				ToPort     *int
+				IpRanges   []struct{ CidrIp string }
+				Ipv6Ranges []struct{ CidrIpv6 string }
+			}
+		}
+	}
+	if json.Unmarshal(results["DescribeSecurityGroups"], &groups) == nil {
+		for i, group := range groups.SecurityGroups {
+			for j, permission := range group.IpPermissions {
+				if !open_to_internet(permission.IpRanges, permission.Ipv6Ranges) || utils.Find([]string{"icmp", "icmpv6", "1", "58"}, permission.IpProtocol) {
+					continue
+				}
+				path := fmt.Sprintf("$.SecurityGroups[%d].IpPermissions[%d]", i, j)
+				from, to := -1, -1
+				if permission.IpProtocol != "-1" && permission.FromPort != nil && permission.ToPort != nil && *permission.FromPort != -1 {
+					from, to = *permission.FromPort, *permission.ToPort
+				}
+				analyzer.open[group.GroupId] = append(analyzer.open[group.GroupId], open_permission{from, to, path})
+
+				finding := Finding{
+					ID:       "ec2-open-security-group",
+					Service:  "ec2",
+					Region:   region,
+					Resource: fmt.Sprintf("arn:aws:ec2:%s:%s:security-group/%s", region, group.OwnerId, group.GroupId),
+					ApiCall:  "DescribeSecurityGroups",
+					Evidence: path,
+				}
+				switch {
+				case from == -1:
+					finding.Severity = SEVERITY_CRITICAL
+					finding.Description = fmt.Sprintf("security group %s (%s) allows all traffic from the internet", group.GroupId, group.GroupName)
+				default:
+					ports := open_sensitive_ports(from, to)
+					if len(ports) == 0 {
+						continue
+					}
+					finding.Severity = SEVERITY_HIGH
+					finding.Description = fmt.Sprintf("security group %s (%s) allows %s from the internet", group.GroupId, group.GroupName, strings.Join(ports, ", "))
+				}
+				analyzer.add(finding)
+			}
+		}
+	}
+
+	var images struct {
+		Images []struct {
+			ImageId string
+			Name    string
+			Public  bool
+		}
+	}
+	if json.Unmarshal(results["DescribeImages"], &images) == nil {
+		for i, image := range images.Images {
+			if image.Public {
+				analyzer.add(Finding{
+					ID:          "ec2-public-ami",
+					Severity:    SEVERITY_HIGH,
+					Service:     "ec2",
+					Region:      region,
+					Resource:    fmt.Sprintf("arn:aws:ec2:%s::image/%s", region, image.ImageId),
+					Description: fmt.Sprintf("AMI %s (%s) is public, anyone can launch it and read its volumes", image.ImageId, image.Name),
+					ApiCall:     "DescribeImages",
+					Evidence:    fmt.Sprintf("$.Images[%d].Public", i),
+				})
+			}
+		}
+	}
+
+	var snapshots []struct {
+		Output *struct {
+			SnapshotId              string
+			CreateVolumePermissions []struct{ Group string }
+		}
+	}
+	if json.Unmarshal(results["DescribeSnapshotAttribute"], &snapshots) == nil {
+		for i, record := range snapshots {
+			if record.Output == nil {
+				continue
+			}
+			for j, permission := range record.Output.CreateVolumePermissions {
+				if permission.Group == "all" {
+					analyzer.add(Finding{
+						ID:          "ec2-public-snapshot",
+						Severity:    SEVERITY_CRITICAL,
+						Service:     "ec2",
+						Region:      region,
+						Resource:    fmt.Sprintf("arn:aws:ec2:%s::snapshot/%s", region, record.Output.SnapshotId),
+						Description: fmt.Sprintf("EBS snapshot %s is public, anyone can create a volume from it", record.Output.SnapshotId),
+						ApiCall:     "DescribeSnapshotAttribute",
+						Evidence:    fmt.Sprintf("$[%d].Output.CreateVolumePermissions[%d]", i, j),
+					})
+				}
+			}
+		}
+	}
+}
+
+func (analyzer *exposure_analyzer) rds(region string, results map[string]json.RawMessage) {
+	var instances struct {
+		DBInstances []struct {
+			DBInstanceIdentifier string
+			DBInstanceArn        string
+			Engine               string
+			PubliclyAccessible   bool
+			Endpoint             *struct{ Port int }
+			VpcSecurityGroups    []struct{ VpcSecurityGroupId string }
+		}
+	}
+	if json.Unmarshal(results["DescribeDBInstances"], &instances) == nil {
+		for i, instance := range instances.DBInstances {
+			if !instance.PubliclyAccessible {
+				continue
+			}
+			finding := Finding{
+				ID:          "rds-public-instance",
+				Severity:    SEVERITY_HIGH,
+				Service:     "rds",
+				Region:      region,
+				Resource:    instance.DBInstanceArn,
+				Description: fmt.Sprintf("%s instance %s is publicly accessible", instance.Engine, instance.DBInstanceIdentifier),
+				ApiCall:     "DescribeDBInstances",
+				Evidence:    fmt.Sprintf("$.DBInstances[%d].PubliclyAccessible", i),
+			}
+			if instance.Endpoint != nil {
+				for _, group := range instance.VpcSecurityGroups {
+					if analyzer.port_open(group.VpcSecurityGroupId, instance.Endpoint.Port) {
+						finding.Severity = SEVERITY_CRITICAL
+						finding.Description += fmt.Sprintf(" and security group %s allows port %d from the internet", group.VpcSecurityGroupId, instance.Endpoint.Port)
+						break
+					}
+				}
+			}
+			analyzer.add(finding)
+		}
+	}
+
+	snapshot_arns := make(map[string]string)
+	var snapshots struct {
+		DBSnapshots        []struct{ DBSnapshotIdentifier, DBSnapshotArn string }
+		DBClusterSnapshots []struct{ DBClusterSnapshotIdentifier, DBClusterSnapshotArn string }
+	}
+	if json.Unmarshal(results["DescribeDBSnapshots"], &snapshots) == nil {
+		for _, snapshot := range snapshots.DBSnapshots {
+			snapshot_arns[snapshot.DBSnapshotIdentifier] = snapshot.DBSnapshotArn
+		}
+	}
+	if json.Unmarshal(results["DescribeDBClusterSnapshots"], &snapshots) == nil {
+		for _, snapshot := range snapshots.DBClusterSnapshots {
+			snapshot_arns[snapshot.DBClusterSnapshotIdentifier] = snapshot.DBClusterSnapshotArn
+		}
+	}
+
+	type snapshot_attributes struct {
+		AttributeName   string
+		AttributeValues []string
+	}
+	var instance_attributes []struct {
+		Output *struct {
+			DBSnapshotAttributesResult struct {
+				DBSnapshotIdentifier string
+				DBSnapshotAttributes []snapshot_attributes
+			}
+		}
+	}
+	if json.Unmarshal(results["DescribeDBSnapshotAttributes"], &instance_attributes) == nil {
+		for i, record := range instance_attributes {
+			if record.Output == nil {
+				continue
+			}
+			result := record.Output.DBSnapshotAttributesResult
+			for j, attribute := range result.DBSnapshotAttributes {
+				if attribute.AttributeName == "restore" && utils.Find(attribute.AttributeValues, "all") {
+					analyzer.add(public_rds_snapshot(region, "DescribeDBSnapshotAttributes", result.DBSnapshotIdentifier, snapshot_arns,
+						fmt.Sprintf("$[%d].Output.DBSnapshotAttributesResult.DBSnapshotAttributes[%d]", i, j)))
+				}
+			}
+		}
+	}
+	var cluster_attributes []struct {
+		Output *struct {
+			DBClusterSnapshotAttributesResult struct {
+				DBClusterSnapshotIdentifier string
+				DBClusterSnapshotAttributes []snapshot_attributes
+			}
+		}
+	}
+	if json.Unmarshal(results["DescribeDBClusterSnapshotAttributes"], &cluster_attributes) == nil {
+		for i, record := range cluster_attributes {
+			if record.Output == nil {
+				continue
+			}
+			result := record.Output.DBClusterSnapshotAttributesResult
+			for j, attribute := range result.DBClusterSnapshotAttributes {
+				if attribute.AttributeName == "restore" && utils.Find(attribute.AttributeValues, "all") {
+					analyzer.add(public_rds_snapshot(region, "DescribeDBClusterSnapshotAttributes", result.DBClusterSnapshotIdentifier, snapshot_arns,
+						fmt.Sprintf("$[%d].Output.DBClusterSnapshotAttributesResult.DBClusterSnapshotAttributes[%d]", i, j)))
+				}
+			}
+		}
+	}
+}
+
+func public_rds_snapshot(region, apicall, identifier string, arns map[string]string, path string) Finding {
+	resource := arns[identifier]
+	if resource == "" {
+		resource = identifier
+	}
+	return Finding{
+		ID:          "rds-public-snapshot",
+		Severity:    SEVERITY_CRITICAL,
+		Service:     "rds",
+		Region:      region,
+		Resource:    resource,
+		Description: fmt.Sprintf("RDS snapshot %s is public, any account can restore it", identifier),
+		ApiCall:     apicall,
+		Evidence:    path,
+	}
+}
+
+// s3 combines the public access block, the policy status, the policy and the ACL of every bucket
+func (analyzer *exposure_analyzer) s3(region string, results map[string]json.RawMessage) {
+	type bucket_input struct{ Bucket string }
+
+	blocks := make(map[string]map[string]bool)
+	var public_access_blocks []struct {
+		Input  bucket_input
+		Output *struct{ PublicAccessBlockConfiguration map[string]bool }
+	}
+	if json.Unmarshal(results["GetPublicAccessBlock"], &public_access_blocks) == nil {
+		for _, record := range public_access_blocks {
+			if record.Output != nil {
+				blocks[record.Input.Bucket] = record.Output.PublicAccessBlockConfiguration
+			}
+		}
+	}
+
+	// GetBucketPolicyStatus evaluates the conditions of the policy, it takes precedence over the offline check
+	status := make(map[string]bool)
+	var statuses []struct {
+		Input  bucket_input
+		Output *struct{ PolicyStatus struct{ IsPublic bool } }
+	}
+	if json.Unmarshal(results["GetBucketPolicyStatus"], &statuses) == nil {
+		for i, record := range statuses {
+			if record.Output == nil {
+				continue
+			}
+			status[record.Input.Bucket] = true
+			if record.Output.PolicyStatus.IsPublic && !blocks[record.Input.Bucket]["RestrictPublicBuckets"] {
+				analyzer.add(public_bucket(region, record.Input.Bucket, SEVERITY_CRITICAL, "its bucket policy is public", "GetBucketPolicyStatus",
+					fmt.Sprintf("$[%d].Output.PolicyStatus.IsPublic", i)))
+			}
+		}
+	}
+
+	var policies []struct {
+		Input  bucket_input
+		Output *struct{ Policy json.RawMessage }
+	}
+	if json.Unmarshal(results["GetBucketPolicy"], &policies) == nil {
+		for i, record := range policies {
+			bucket := record.Input.Bucket
+			if record.Output == nil || status[bucket] || blocks[bucket]["RestrictPublicBuckets"] {
+				continue
+			}
+			trust := policy.TrustsEveryone(parse_document(record.Output.Policy))
+			if !trust.Trusted {
+				continue
+			}
+			severity, description := SEVERITY_CRITICAL, "its bucket policy allows everyone (statement "+trust.Statement+")"
+			if trust.Conditional {
+				severity, description = SEVERITY_MEDIUM, "its bucket policy allows everyone under conditions (statement "+trust.Statement+")"
+			}
+			analyzer.add(public_bucket(region, bucket, severity, description, "GetBucketPolicy", fmt.Sprintf("$[%d].Output.Policy", i)))
+		}
+	}
+
+	var acls []struct {
+		Input  bucket_input
+		Output *struct {
+			Grants []struct {
+				Grantee    struct{ URI string }
+				Permission string
+			}
+		}
+	}
+	if json.Unmarshal(results["GetBucketAcl"], &acls) == nil {
+		for i, record := range acls {
+			bucket := record.Input.Bucket
+			if record.Output == nil || blocks[bucket]["IgnorePublicAcls"] {
+				continue
+			}
+			for j, grant := range record.Output.Grants {
+				var severity, grantee string
+				switch {
+				case strings.HasSuffix(grant.Grantee.URI, "/global/AllUsers"):
+					severity, grantee = SEVERITY_CRITICAL, "everyone"
+				case strings.HasSuffix(grant.Grantee.URI, "/global/AuthenticatedUsers"):
+					severity, grantee = SEVERITY_HIGH, "any AWS account"
+				default:
+					continue
+				}
+				analyzer.add(public_bucket(region, bucket, severity, fmt.Sprintf("its ACL grants %s to %s", grant.Permission, grantee), "GetBucketAcl",
+					fmt.Sprintf("$[%d].Output.Grants[%d]", i, j)))
+			}
+		}
+	}
+}
+
+func public_bucket(region, bucket, severity, reason, apicall, path string) Finding {
+	return Finding{
+		ID:          "s3-public-bucket",
+		Severity:    severity,
+		Service:     "s3",
+		Region:      region,
+		Resource:    "arn:aws:s3:::" + bucket,
+		Description: fmt.Sprintf("bucket %s is public: %s", bucket, reason),
+		ApiCall:     apicall,
+		Evidence:    path,
+	}
+}
+
+func (analyzer *exposure_analyzer) lambda(region string, results map[string]json.RawMessage) {
+	var urls []struct {
+		Output *struct {
+			FunctionUrlConfigs []struct {
+				FunctionArn string
+				FunctionUrl string
+				AuthType    string
+			}
+		}
+	}
+	if json.Unmarshal(results["ListFunctionUrlConfigs"], &urls) == nil {
+		for i, record := range urls {
+			if record.Output == nil {
+				continue
+			}
+			for j, url := range record.Output.FunctionUrlConfigs {
+				if url.AuthType == "NONE" {
+					analyzer.add(Finding{
+						ID:          "lambda-public-function-url",
+						Severity:    SEVERITY_HIGH,
+						Service:     "lambda",
+						Region:      region,
+						Resource:    url.FunctionArn,
+						Description: fmt.Sprintf("function URL %s requires no authentication", url.FunctionUrl),
+						ApiCall:     "ListFunctionUrlConfigs",
+						Evidence:    fmt.Sprintf("$[%d].Output.FunctionUrlConfigs[%d].AuthType", i, j),
+					})
+				}
+			}
+		}
+	}
+
+	var policies []struct {
+		Input  struct{ FunctionName string }
+		Output *struct{ Policy json.RawMessage }
+	}
+	if json.Unmarshal(results["GetPolicy"], &policies) == nil {
+		for i, record := range policies {
+			if record.Output == nil {
+				continue
+			}
+			// conditions (aws:SourceArn, lambda:FunctionUrlAuthType ...) usually scope public statements down
+			if trust := policy.TrustsEveryone(parse_document(record.Output.Policy)); trust.Trusted && !trust.Conditional {
+				analyzer.add(Finding{
+					ID:          "lambda-public-policy",
+					Severity:    SEVERITY_HIGH,
+					Service:     "lambda",
+					Region:      region,
+					Resource:    analyzer.arn("lambda", region, "function:"+record.Input.FunctionName),
+					Description: fmt.Sprintf("the resource policy of function %s allows everyone (statement %s)", record.Input.FunctionName, trust.Statement),
+					ApiCall:     "GetPolicy",
+					Evidence:    fmt.Sprintf("$[%d].Output.Policy", i),
+				})
+			}
+		}
+	}
+}
+
+// apigateway reports the REST APIs with methods that need neither an authorizer nor an API key
+func (analyzer *exposure_analyzer) apigateway(region string, results map[string]json.RawMessage) {
+	type rest_api struct {
+		Id                    string
+		Name                  string
+		Policy                string
+		EndpointConfiguration *struct{ Types []string }
+	}
+	apis := make(map[string]rest_api)
+	var listed struct{ Items []rest_api }
+	if json.Unmarshal(results["GetRestApis"], &listed) == nil {
+		for _, api := range listed.Items {
+			apis[api.Id] = api
+		}
+	}
+
+	var resources []struct {
+		Input  struct{ RestApiId string }
+		Output *struct {
+			Items []struct {
+				Path            string
+				ResourceMethods map[string]struct {
+					AuthorizationType string
+					ApiKeyRequired    bool
+				}
+			}
+		}
+	}
+	if json.Unmarshal(results["GetResources"], &resources) != nil {
+		return
+	}
+	for i, record := range resources {
+		api := apis[record.Input.RestApiId]
+		if record.Output == nil || (api.EndpointConfiguration != nil && utils.Find(api.EndpointConfiguration.Types, "PRIVATE")) {
+			continue
+		}
+		var open []string
+		path := ""
+		for j, resource := range record.Output.Items {
+			methods := make([]string, 0, len(resource.ResourceMethods))
+			for method := range resource.ResourceMethods {
+				methods = append(methods, method)
+			}
+			sort.Strings(methods)
+			for _, method := range methods {
+				settings := resource.ResourceMethods[method]
+				if method == "OPTIONS" || settings.AuthorizationType != "NONE" || settings.ApiKeyRequired {
+					continue
+				}
+				if path == "" {
+					path = fmt.Sprintf("$[%d].Output.Items[%d].ResourceMethods.%s", i, j, method)
+				}
+				open = append(open, method+" "+resource.Path)
+			}
+		}
+		if len(open) == 0 {
+			continue
+		}
+		finding := Finding{
+			ID:          "apigateway-no-authorizer",
+			Severity:    SEVERITY_MEDIUM,
+			Service:     "apigateway",
+			Region:      region,
+			Resource:    fmt.Sprintf("arn:aws:apigateway:%s::/restapis/%s", region, record.Input.RestApiId),
+			Description: fmt.Sprintf("REST API %s (%s) has %d methods without authorization: %s", record.Input.RestApiId, api.Name, len(open), summarize(open, 5)),
+			ApiCall:     "GetResources",
+			Evidence:    path,
+		}
+		if api.Policy != "" {
+			finding.Severity = SEVERITY_LOW
+			finding.Description += ", a resource policy may restrict the callers"
+		}
+		analyzer.add(finding)
+	}
+}
+
+// apigatewayv2 reports the HTTP APIs routes (the $connect route of WebSocket APIs) without authorization
+func (analyzer *exposure_analyzer) apigatewayv2(region string, results map[string]json.RawMessage) {
+	type http_api struct {
+		ApiId                     string
+		Name                      string
+		ProtocolType              string
+		DisableExecuteApiEndpoint bool
+	}
+	apis := make(map[string]http_api)
+	var listed struct{ Items []http_api }
+	if json.Unmarshal(results["GetApis"], &listed) == nil {
+		for _, api := range listed.Items {
+			apis[api.ApiId] = api
+		}
+	}
+
+	var routes []struct {
+		Input  struct{ ApiId string }
+		Output *struct {
+			Items []struct {
+				RouteKey          string
+				AuthorizationType string
+				ApiKeyRequired    bool
+			}
+		}
+	}
+	if json.Unmarshal(results["GetRoutes"], &routes) != nil {
+		return
+	}
+	for i, record := range routes {
+		api := apis[record.Input.ApiId]
+		if record.Output == nil || api.DisableExecuteApiEndpoint {
+			continue
+		}
+		var open []string
+		path := ""
+		for j, route := range record.Output.Items {
+			if api.ProtocolType == "WEBSOCKET" && route.RouteKey != "$connect" {
+				continue
+			}
+			if (route.AuthorizationType != "NONE" && route.AuthorizationType != "") || route.ApiKeyRequired || strings.HasPrefix(route.RouteKey, "OPTIONS ") {
+				continue
+			}
+			if path == "" {
+				path = fmt.Sprintf("$[%d].Output.Items[%d].AuthorizationType", i, j)
+			}
+			open = append(open, route.RouteKey)
+		}
+		if len(open) == 0 {
+			continue
+		}
+		analyzer.add(Finding{
+			ID:          "apigateway-no-authorizer",
+			Severity:    SEVERITY_MEDIUM,
+			Service:     "apigatewayv2",
+			Region:      region,
+			Resource:    fmt.Sprintf("arn:aws:apigateway:%s::/apis/%s", region, record.Input.ApiId),
+			Description: fmt.Sprintf("%s API %s (%s) has %d routes without authorization: %s", api.ProtocolType, record.Input.ApiId, api.Name, len(open), summarize(open, 5)),
+			ApiCall:     "GetRoutes",
+			Evidence:    path,
+		})
+	}
+}
+
+func (analyzer *exposure_analyzer) ecr(region string, results map[string]json.RawMessage) {
+	var policies []struct {
+		Output *struct {
+			RegistryId     string
+			RepositoryName string
+			PolicyText     json.RawMessage
+		}
+	}
+	if json.Unmarshal(results["GetRepositoryPolicy"], &policies) != nil {
+		return
+	}
+	for i, record := range policies {
+		if record.Output == nil {
+			continue
+		}
+		if trust := policy.TrustsEveryone(parse_document(record.Output.PolicyText)); trust.Trusted && !trust.Conditional {
+			analyzer.add(Finding{
+				ID:          "ecr-public-repository",
+				Severity:    SEVERITY_HIGH,
+				Service:     "ecr",
+				Region:      region,
+				Resource:    fmt.Sprintf("arn:aws:ecr:%s:%s:repository/%s", region, record.Output.RegistryId, record.Output.RepositoryName),
+				Description: fmt.Sprintf("the policy of repository %s allows everyone (statement %s)", record.Output.RepositoryName, trust.Statement),
+				ApiCall:     "GetRepositoryPolicy",
+				Evidence:    fmt.Sprintf("$[%d].Output.PolicyText", i),
+			})
+		}
+	}
+}
+
+// elb reports the internet-facing classic load balancers, often intended: low severity
+func (analyzer *exposure_analyzer) elb(region string, results map[string]json.RawMessage) {
+	var balancers struct {
+		LoadBalancerDescriptions []struct {
+			LoadBalancerName string
+			DNSName          string
+			Scheme           string
+		}
+	}
+	if json.Unmarshal(results["DescribeLoadBalancers"], &balancers) != nil {
+		return
+	}
+	for i, balancer := range balancers.LoadBalancerDescriptions {
+		if balancer.Scheme == "internet-facing" {
+			analyzer.add(Finding{
+				ID:          "elb-internet-facing",
+				Severity:    SEVERITY_LOW,
+				Service:     "elb",
+				Region:      region,
+				Resource:    analyzer.arn("elasticloadbalancing", region, "loadbalancer/"+balancer.LoadBalancerName),
+				Description: fmt.Sprintf("classic load balancer %s is internet-facing (%s)", balancer.LoadBalancerName, balancer.DNSName),
+				ApiCall:     "DescribeLoadBalancers",
+				Evidence:    fmt.Sprintf("$.LoadBalancerDescriptions[%d].Scheme", i),
+			})
+		}
+	}
+}
+
+func (analyzer *exposure_analyzer) elbv2(region string, results map[string]json.RawMessage) {
+	var balancers struct {
+		LoadBalancers []struct {
+			LoadBalancerArn  string
+			LoadBalancerName string
+			DNSName          string
+			Scheme           string
+			Type             string
+		}
+	}
+	if json.Unmarshal(results["DescribeLoadBalancers"], &balancers) != nil {
+		return
+	}
+	for i, balancer := range balancers.LoadBalancers {
+		if balancer.Scheme == "internet-facing" {
+			analyzer.add(Finding{
+				ID:          "elb-internet-facing",
+				Severity:    SEVERITY_LOW,
+				Service:     "elbv2",
+				Region:      region,
+				Resource:    balancer.LoadBalancerArn,
+				Description: fmt.Sprintf("%s load balancer %s is internet-facing (%s)", balancer.Type, balancer.LoadBalancerName, balancer.DNSName),
+				ApiCall:     "DescribeLoadBalancers",
+				Evidence:    fmt.Sprintf("$.LoadBalancers[%d].Scheme", i),
+			})
+		}
+	}
+}
+
+// port_open reports whether a rule of the security group opens the port to the internet
+func (analyzer *exposure_analyzer) port_open(group string, port int) bool {
+	for _, permission := range analyzer.open[group] {
+		if permission.from == -1 || (permission.from <= port && port <= permission.to) {
+			return true
+		}
+	}
+	return false
+}
+
+// arn builds the ARN of a resource of the account, the bare resource when the account is unknown
+func (analyzer *exposure_analyzer) arn(service, region, resource string) string {
+	if analyzer.account == "" {
+		return resource
+	}
+	return fmt.Sprintf("arn:aws:%s:%s:%s:%s", service, region, analyzer.account, resource)
+}
+
+func open_to_internet(ipv4 []struct{ CidrIp string }, ipv6 []struct{ CidrIpv6 string }) bool {
+	for _, ip_range := range ipv4 {
+		if ip_range.CidrIp == "0.0.0.0/0" {
+			return true
+		}
+	}
+	for _, ip_range := range ipv6 {
+		if ip_range.CidrIpv6 == "::/0" {
+			return true
+		}
+	}
+	return false
+}
+
+// open_sensitive_ports lists the sensitive ports of a port range
+func open_sensitive_ports(from, to int) []string {
+	var ports []int
+	for port := range sensitive_ports {
+		if from <= port && port <= to {
+			ports = append(ports, port)
+		}
+	}
+	sort.Ints(ports)
+	var names []string
+	for _, port := range ports {
+		names = append(names, fmt.Sprintf("%d/%s", port, sensitive_ports[port]))
+	}
+	if len(names) > 5 {
+		return []string{fmt.Sprintf("ports %d-%d (%s)", from, to, summarize(names, 5))}
+	}
+	return names
+}
+
+// stored_account returns the account of the identity recorded by sts:GetCallerIdentity
+func stored_account() string {
+	results, err := servicemaster.LoadResults(utils.GLOBAL_REGION, "sts", false)
+	if err != nil {
+		return ""
+	}
+	var identity struct{ Account string }
+	json.Unmarshal(results["GetCallerIdentity"], &identity)
+	return identity.Account
+}
+
+func summarize(values []string, max int) string {
+	if len(values) <= max {
+		return strings.Join(values, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(values[:max], ", "), len(values)-max)
+}
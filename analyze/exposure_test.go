@@ -0,0 +1,210 @@
+package analyze
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+const everyone_policy = `{\"Statement\": [{\"Sid\": \"Public\", \"Effect\": \"Allow\", \"Principal\": \"*\", \"Action\": \"*\", \"Resource\": \"*\"}]}`
+
+func TestAnalyzeExposure(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  map[string]map[string]string // service -> api call -> stored response
+		findings []string                     // "id severity resource evidence"
+	}{
+		{
+			name: "security groups",
+			results: map[string]map[string]string{"ec2": {"DescribeSecurityGroups": `{"SecurityGroups": [
+				{"GroupId": "sg-ssh", "OwnerId": "111111111111", "IpPermissions": [{"IpProtocol": "tcp", "FromPort": 22, "ToPort": 22, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}]},
+				{"GroupId": "sg-all", "OwnerId": "111111111111", "IpPermissions": [{"IpProtocol": "-1", "Ipv6Ranges": [{"CidrIpv6": "::/0"}]}]},
+				{"GroupId": "sg-range", "OwnerId": "111111111111", "IpPermissions": [{"IpProtocol": "tcp", "FromPort": 0, "ToPort": 65535, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}]},
+				{"GroupId": "sg-https", "OwnerId": "111111111111", "IpPermissions": [{"IpProtocol": "tcp", "FromPort": 443, "ToPort": 443, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}]},
+				{"GroupId": "sg-private", "OwnerId": "111111111111", "IpPermissions": [{"IpProtocol": "tcp", "FromPort": 22, "ToPort": 22, "IpRanges": [{"CidrIp": "10.0.0.0/8"}]}]},
+				{"GroupId": "sg-ping", "OwnerId": "111111111111", "IpPermissions": [{"IpProtocol": "icmp", "FromPort": 8, "ToPort": -1, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}]}
+			]}`}},
+			findings: []string{
+				"ec2-open-security-group critical arn:aws:ec2:eu-west-1:111111111111:security-group/sg-all $.SecurityGroups[1].IpPermissions[0]",
+				"ec2-open-security-group high arn:aws:ec2:eu-west-1:111111111111:security-group/sg-range $.SecurityGroups[2].IpPermissions[0]",
+				"ec2-open-security-group high arn:aws:ec2:eu-west-1:111111111111:security-group/sg-ssh $.SecurityGroups[0].IpPermissions[0]",
+			},
+		},
+		{
+			name: "images and snapshots",
+			results: map[string]map[string]string{"ec2": {
+				"DescribeImages":            `{"Images": [{"ImageId": "ami-private", "Public": false}, {"ImageId": "ami-public", "Name": "golden", "Public": true}]}`,
+				"DescribeSnapshotAttribute": `[{"Input": {"SnapshotId": "snap-1"}, "Output": {"SnapshotId": "snap-1", "CreateVolumePermissions": [{"UserId": "222222222222"}, {"Group": "all"}]}}, {"Input": {"SnapshotId": "snap-2"}, "Error": {"Class": "access_denied"}}, {"Input": {"SnapshotId": "snap-3"}, "Output": {"SnapshotId": "snap-3", "CreateVolumePermissions": []}}]`,
+			}},
+			findings: []string{
+				"ec2-public-ami high arn:aws:ec2:eu-west-1::image/ami-public $.Images[1].Public",
+				"ec2-public-snapshot critical arn:aws:ec2:eu-west-1::snapshot/snap-1 $[0].Output.CreateVolumePermissions[1]",
+			},
+		},
+		{
+			name: "rds instances",
+			results: map[string]map[string]string{
+				"ec2": {"DescribeSecurityGroups": `{"SecurityGroups": [{"GroupId": "sg-db", "IpPermissions": [{"IpProtocol": "tcp", "FromPort": 5400, "ToPort": 5500, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}]}]}`},
+				"rds": {"DescribeDBInstances": `{"DBInstances": [
+					{"DBInstanceIdentifier": "open", "DBInstanceArn": "arn:aws:rds:eu-west-1:111111111111:db:open", "Engine": "postgres", "PubliclyAccessible": true, "Endpoint": {"Port": 5432}, "VpcSecurityGroups": [{"VpcSecurityGroupId": "sg-other"}, {"VpcSecurityGroupId": "sg-db"}]},
+					{"DBInstanceIdentifier": "closed", "DBInstanceArn": "arn:aws:rds:eu-west-1:111111111111:db:closed", "Engine": "mysql", "PubliclyAccessible": true, "Endpoint": {"Port": 3306}, "VpcSecurityGroups": [{"VpcSecurityGroupId": "sg-db"}]},
+					{"DBInstanceIdentifier": "private", "DBInstanceArn": "arn:aws:rds:eu-west-1:111111111111:db:private", "PubliclyAccessible": false}
+				]}`},
+			},
+			findings: []string{
+				"ec2-open-security-group high arn:aws:ec2:eu-west-1::security-group/sg-db $.SecurityGroups[0].IpPermissions[0]",
+				"rds-public-instance critical arn:aws:rds:eu-west-1:111111111111:db:open $.DBInstances[0].PubliclyAccessible",
+				"rds-public-instance high arn:aws:rds:eu-west-1:111111111111:db:closed $.DBInstances[1].PubliclyAccessible",
+			},
+		},
+		{
+			name: "rds snapshots",
+			results: map[string]map[string]string{"rds": {
+				"DescribeDBSnapshots":                 `{"DBSnapshots": [{"DBSnapshotIdentifier": "nightly", "DBSnapshotArn": "arn:aws:rds:eu-west-1:111111111111:snapshot:nightly"}]}`,
+				"DescribeDBSnapshotAttributes":        `[{"Input": {"DBSnapshotIdentifier": "nightly"}, "Output": {"DBSnapshotAttributesResult": {"DBSnapshotIdentifier": "nightly", "DBSnapshotAttributes": [{"AttributeName": "restore", "AttributeValues": ["all"]}]}}}, {"Input": {"DBSnapshotIdentifier": "shared"}, "Output": {"DBSnapshotAttributesResult": {"DBSnapshotIdentifier": "shared", "DBSnapshotAttributes": [{"AttributeName": "restore", "AttributeValues": ["222222222222"]}]}}}]`,
+				"DescribeDBClusterSnapshotAttributes": `[{"Input": {"DBClusterSnapshotIdentifier": "cluster"}, "Output": {"DBClusterSnapshotAttributesResult": {"DBClusterSnapshotIdentifier": "cluster", "DBClusterSnapshotAttributes": [{"AttributeName": "restore", "AttributeValues": ["all"]}]}}}]`,
+			}},
+			findings: []string{
+				"rds-public-snapshot critical arn:aws:rds:eu-west-1:111111111111:snapshot:nightly $[0].Output.DBSnapshotAttributesResult.DBSnapshotAttributes[0]",
+				"rds-public-snapshot critical cluster $[0].Output.DBClusterSnapshotAttributesResult.DBClusterSnapshotAttributes[0]",
+			},
+		},
+		{
+			name: "s3 buckets",
+			results: map[string]map[string]string{"s3": {
+				"GetPublicAccessBlock":  `[{"Input": {"Bucket": "blocked"}, "Output": {"PublicAccessBlockConfiguration": {"RestrictPublicBuckets": true, "IgnorePublicAcls": true}}}]`,
+				"GetBucketPolicyStatus": `[{"Input": {"Bucket": "status"}, "Output": {"PolicyStatus": {"IsPublic": true}}}, {"Input": {"Bucket": "evaluated"}, "Output": {"PolicyStatus": {"IsPublic": false}}}, {"Input": {"Bucket": "blocked"}, "Output": {"PolicyStatus": {"IsPublic": true}}}]`,
+				"GetBucketPolicy": `[
+					{"Input": {"Bucket": "status"}, "Output": {"Policy": "` + everyone_policy + `"}},
+					{"Input": {"Bucket": "evaluated"}, "Output": {"Policy": "` + everyone_policy + `"}},
+					{"Input": {"Bucket": "offline"}, "Output": {"Policy": "` + everyone_policy + `"}},
+					{"Input": {"Bucket": "conditional"}, "Output": {"Policy": "{\"Statement\": {\"Effect\": \"Allow\", \"Principal\": {\"AWS\": \"*\"}, \"Action\": \"s3:GetObject\", \"Resource\": \"*\", \"Condition\": {\"StringEquals\": {\"aws:PrincipalOrgID\": \"o-1\"}}}}"}},
+					{"Input": {"Bucket": "missing"}, "Error": {"Class": "not_found"}}
+				]`,
+				"GetBucketAcl": `[
+					{"Input": {"Bucket": "acl"}, "Output": {"Grants": [{"Grantee": {"ID": "owner"}, "Permission": "FULL_CONTROL"}, {"Grantee": {"URI": "http://acs.amazonaws.com/groups/global/AllUsers"}, "Permission": "READ"}, {"Grantee": {"URI": "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"}, "Permission": "WRITE"}]}},
+					{"Input": {"Bucket": "blocked"}, "Output": {"Grants": [{"Grantee": {"URI": "http://acs.amazonaws.com/groups/global/AllUsers"}, "Permission": "READ"}]}}
+				]`,
+			}},
+			findings: []string{
+				"s3-public-bucket critical arn:aws:s3:::acl $[0].Output.Grants[1]",
+				"s3-public-bucket critical arn:aws:s3:::offline $[2].Output.Policy",
+				"s3-public-bucket critical arn:aws:s3:::status $[0].Output.PolicyStatus.IsPublic",
+				"s3-public-bucket high arn:aws:s3:::acl $[0].Output.Grants[2]",
+				"s3-public-bucket medium arn:aws:s3:::conditional $[3].Output.Policy",
+			},
+		},
+		{
+			name: "lambda functions",
+			results: map[string]map[string]string{"lambda": {
+				"ListFunctionUrlConfigs": `[{"Input": {"FunctionName": "open"}, "Output": {"FunctionUrlConfigs": [{"FunctionArn": "arn:aws:lambda:eu-west-1:111111111111:function:open", "FunctionUrl": "https://open.lambda-url.eu-west-1.on.aws/", "AuthType": "NONE"}]}}, {"Input": {"FunctionName": "iam"}, "Output": {"FunctionUrlConfigs": [{"FunctionArn": "arn:aws:lambda:eu-west-1:111111111111:function:iam", "AuthType": "AWS_IAM"}]}}]`,
+				"GetPolicy":              `[{"Input": {"FunctionName": "public"}, "Output": {"Policy": "` + everyone_policy + `"}}, {"Input": {"FunctionName": "s3-trigger"}, "Output": {"Policy": "{\"Statement\": [{\"Sid\": \"s3\", \"Effect\": \"Allow\", \"Principal\": {\"Service\": \"s3.amazonaws.com\"}, \"Action\": \"lambda:InvokeFunction\", \"Resource\": \"*\"}]}"}}, {"Input": {"FunctionName": "url"}, "Output": {"Policy": "{\"Statement\": [{\"Effect\": \"Allow\", \"Principal\": \"*\", \"Action\": \"lambda:InvokeFunctionUrl\", \"Resource\": \"*\", \"Condition\": {\"StringEquals\": {\"lambda:FunctionUrlAuthType\": \"AWS_IAM\"}}}]}"}}]`,
+			}},
+			findings: []string{
+				"lambda-public-function-url high arn:aws:lambda:eu-west-1:111111111111:function:open $[0].Output.FunctionUrlConfigs[0].AuthType",
+				"lambda-public-policy high arn:aws:lambda:eu-west-1:111111111111:function:public $[0].Output.Policy",
+			},
+		},
+		{
+			name: "rest apis",
+			results: map[string]map[string]string{"apigateway": {
+				"GetRestApis": `{"Items": [{"Id": "open", "Name": "shop"}, {"Id": "private", "EndpointConfiguration": {"Types": ["PRIVATE"]}}, {"Id": "policy", "Policy": "{}"}, {"Id": "secured"}]}`,
+				"GetResources": `[
+					{"Input": {"RestApiId": "open"}, "Output": {"Items": [{"Path": "/", "ResourceMethods": {"OPTIONS": {"AuthorizationType": "NONE"}, "POST": {"AuthorizationType": "AWS_IAM"}}}, {"Path": "/items", "ResourceMethods": {"PUT": {"AuthorizationType": "NONE"}, "GET": {"AuthorizationType": "NONE"}}}]}},
+					{"Input": {"RestApiId": "private"}, "Output": {"Items": [{"Path": "/", "ResourceMethods": {"GET": {"AuthorizationType": "NONE"}}}]}},
+					{"Input": {"RestApiId": "policy"}, "Output": {"Items": [{"Path": "/", "ResourceMethods": {"GET": {"AuthorizationType": "NONE"}}}]}},
+					{"Input": {"RestApiId": "secured"}, "Output": {"Items": [{"Path": "/", "ResourceMethods": {"GET": {"AuthorizationType": "COGNITO_USER_POOLS"}, "POST": {"AuthorizationType": "NONE", "ApiKeyRequired": true}}}]}}
+				]`,
+			}},
+			findings: []string{
+				"apigateway-no-authorizer low arn:aws:apigateway:eu-west-1::/restapis/policy $[2].Output.Items[0].ResourceMethods.GET",
+				"apigateway-no-authorizer medium arn:aws:apigateway:eu-west-1::/restapis/open $[0].Output.Items[1].ResourceMethods.GET",
+			},
+		},
+		{
+			name: "http and websocket apis",
+			results: map[string]map[string]string{"apigatewayv2": {
+				"GetApis": `{"Items": [{"ApiId": "http", "ProtocolType": "HTTP"}, {"ApiId": "ws", "ProtocolType": "WEBSOCKET"}, {"ApiId": "disabled", "ProtocolType": "HTTP", "DisableExecuteApiEndpoint": true}]}`,
+				"GetRoutes": `[
+					{"Input": {"ApiId": "http"}, "Output": {"Items": [{"RouteKey": "OPTIONS /items", "AuthorizationType": "NONE"}, {"RouteKey": "GET /items", "AuthorizationType": "NONE"}, {"RouteKey": "POST /items", "AuthorizationType": "JWT"}]}},
+					{"Input": {"ApiId": "ws"}, "Output": {"Items": [{"RouteKey": "sendmessage", "AuthorizationType": "NONE"}, {"RouteKey": "$connect", "AuthorizationType": "AWS_IAM"}]}},
+					{"Input": {"ApiId": "disabled"}, "Output": {"Items": [{"RouteKey": "GET /", "AuthorizationType": "NONE"}]}}
+				]`,
+			}},
+			findings: []string{
+				"apigateway-no-authorizer medium arn:aws:apigateway:eu-west-1::/apis/http $[0].Output.Items[1].AuthorizationType",
+			},
+		},
+		{
+			name: "ecr repositories",
+			results: map[string]map[string]string{"ecr": {
+				"GetRepositoryPolicy": `[{"Input": {"RepositoryName": "public"}, "Output": {"RegistryId": "111111111111", "RepositoryName": "public", "PolicyText": "` + everyone_policy + `"}}, {"Input": {"RepositoryName": "org"}, "Output": {"RegistryId": "111111111111", "RepositoryName": "org", "PolicyText": "{\"Statement\": [{\"Effect\": \"Allow\", \"Principal\": \"*\", \"Action\": \"ecr:BatchGetImage\", \"Condition\": {\"StringEquals\": {\"aws:PrincipalOrgID\": \"o-1\"}}}]}"}}]`,
+			}},
+			findings: []string{
+				"ecr-public-repository high arn:aws:ecr:eu-west-1:111111111111:repository/public $[0].Output.PolicyText",
+			},
+		},
+		{
+			name: "load balancers",
+			results: map[string]map[string]string{
+				"elb":   {"DescribeLoadBalancers": `{"LoadBalancerDescriptions": [{"LoadBalancerName": "classic", "Scheme": "internet-facing"}, {"LoadBalancerName": "inner", "Scheme": "internal"}]}`},
+				"elbv2": {"DescribeLoadBalancers": `{"LoadBalancers": [{"LoadBalancerArn": "arn:aws:elasticloadbalancing:eu-west-1:111111111111:loadbalancer/app/web/1", "Type": "application", "Scheme": "internet-facing"}, {"LoadBalancerArn": "arn:aws:elasticloadbalancing:eu-west-1:111111111111:loadbalancer/net/inner/2", "Scheme": "internal"}]}`},
+			},
+			findings: []string{
+				"elb-internet-facing low arn:aws:elasticloadbalancing:eu-west-1:111111111111:loadbalancer/app/web/1 $.LoadBalancers[0].Scheme",
+				"elb-internet-facing low arn:aws:elasticloadbalancing:eu-west-1:111111111111:loadbalancer/classic $.LoadBalancerDescriptions[0].Scheme",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		use_run_directory(t)
+		store_results(t, utils.GLOBAL_REGION, "sts", map[string]string{"GetCallerIdentity": `{"Account": "111111111111"}`})
+		for svc, responses := range test.results {
+			store_results(t, "eu-west-1", svc, responses)
+		}
+
+		report := AnalyzeExposure()
+		var findings []string
+		for _, finding := range report.Findings {
+			findings = append(findings, fmt.Sprintf("%s %s %s %s", finding.ID, finding.Severity, finding.Resource, finding.Evidence))
+			if finding.Title == "" || finding.Remediation == "" || finding.Region != "eu-west-1" {
+				t.Errorf("%s: finding %+v", test.name, finding)
+			}
+		}
+		sort.Strings(findings)
+		if strings.Join(findings, "\n") != strings.Join(test.findings, "\n") {
+			t.Errorf("%s: findings\n%s\nwant\n%s", test.name, strings.Join(findings, "\n"), strings.Join(test.findings, "\n"))
+		}
+	}
+}
+
+func TestAnalyzeExposureDescriptions(t *testing.T) {
+	use_run_directory(t)
+	store_results(t, "eu-west-1", "ec2", map[string]string{"DescribeSecurityGroups": `{"SecurityGroups": [
+		{"GroupId": "sg-1", "GroupName": "admin", "IpPermissions": [{"IpProtocol": "tcp", "FromPort": 3389, "ToPort": 3389, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}]},
+		{"GroupId": "sg-2", "GroupName": "wide", "IpPermissions": [{"IpProtocol": "tcp", "FromPort": 1, "ToPort": 10000, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}]}
+	]}`})
+
+	report := AnalyzeExposure()
+	want := []string{
+		"security group sg-1 (admin) allows 3389/rdp from the internet",
+		"security group sg-2 (wide) allows ports 1-10000 (20/ftp-data, 21/ftp, 22/ssh, 23/telnet, 25/smtp and 23 more) from the internet",
+	}
+	var descriptions []string
+	for _, finding := range report.Findings {
+		descriptions = append(descriptions, finding.Description)
+	}
+	sort.Strings(descriptions)
+	if strings.Join(descriptions, "\n") != strings.Join(want, "\n") {
+		t.Errorf("descriptions\n%s\nwant\n%s", strings.Join(descriptions, "\n"), strings.Join(want, "\n"))
+	}
+
+	// every source but the security groups is reported as missing
+	if len(report.Missing) != len(exposure_sources)-1 || utils.Find(report.Missing, "ec2 DescribeSecurityGroups") {
+		t.Errorf("missing %v", report.Missing)
+	}
+}
@@ -0,0 +1,82 @@
+package analyze
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// Severities of the findings, from the most to the least severe
+const (
+	SEVERITY_CRITICAL = "critical"
+	SEVERITY_HIGH     = "high"
+	SEVERITY_MEDIUM   = "medium"
+	SEVERITY_LOW      = "low"
+)
+
+var severity_ranks = map[string]int{SEVERITY_CRITICAL: 0, SEVERITY_HIGH: 1, SEVERITY_MEDIUM: 2, SEVERITY_LOW: 3}
+
+// Finding is the common result of the analyses: exposure, privesc and scan-secrets reports list their findings,
+// the findings command exports them as JSON, CSV or SARIF
+type Finding struct {
+	ID          string // kind of finding (ec2-open-security-group, privesc-admin, secret-jwt ...), the SARIF rule id
+	Title       string
+	Severity    string
+	Resource    string `json:",omitempty"` // ARN, or identifier when the ARN can't be built from the stored results
+	Service     string
+	Region      string `json:",omitempty"`
+	ApiCall     string `json:",omitempty"` // stored api call holding the evidence
+	Evidence    string `json:",omitempty"` // JSON path of the evidence in the stored response of ApiCall
+	Description string
+	Remediation string
+}
+
+// finding_kind is the title and remediation shared by the findings of an ID
+type finding_kind struct {
+	title       string
+	remediation string
+}
+
+// Reports holding findings, read by LoadFindings
+var finding_reports = []string{EXPOSURE_FILE, PRIVESC_FILE, SECRETS_FILE}
+
+// LoadFindings reads the findings of the analysis reports saved to utils.FILEPATH, most severe first,
+// and the reports they were read from
+func LoadFindings() ([]Finding, []string) {
+	var findings []Finding
+	var sources []string
+	for _, filename := range finding_reports {
+		data, err := ioutil.ReadFile(filepath.Join(utils.FILEPATH, filename))
+		if err != nil {
+			continue
+		}
+		var report struct{ Findings []Finding }
+		if json.Unmarshal(data, &report) != nil {
+			continue
+		}
+		findings = append(findings, report.Findings...)
+		sources = append(sources, filename)
+	}
+	SortFindings(findings)
+	return findings, sources
+}
+
+// SortFindings ranks the findings by severity, then by ID, region and resource
+func SortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if severity_ranks[a.Severity] != severity_ranks[b.Severity] {
+			return severity_ranks[a.Severity] < severity_ranks[b.Severity]
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.Resource < b.Resource
+	})
+}
@@ -0,0 +1,485 @@
+package analyze
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/policy"
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// IAMPrincipal is a user, role or group of the IAM model with the documents of its policies
+type IAMPrincipal struct {
+	Type             string // PRINCIPAL_USER, PRINCIPAL_ROLE or PRINCIPAL_GROUP
+	Name             string
+	ARN              string
+	Inline           []policy.Policy
+	AttachedPolicies []string // managed policy ARNs
+	BoundaryARN      string
+	Groups           []string         // group names of a user
+	Trust            *policy.Document // trust policy of a role
+	InstanceProfiles []string         // instance profile ARNs of a role
+}
+
+// PRINCIPAL_GROUP is the type of the IAM groups of the model
+const PRINCIPAL_GROUP = "group"
+
+// ManagedPolicy is a managed policy of the IAM model with the documents of its versions
+type ManagedPolicy struct {
+	ARN      string
+	Name     string
+	Default  *policy.Document
+	Versions map[string]*policy.Document
+}
+
+// IAMModel is the IAM configuration of the account rebuilt from the stored enumeration results
+type IAMModel struct {
+	Account string
+	Users   map[string]*IAMPrincipal
+	Roles   map[string]*IAMPrincipal
+	Groups  map[string]*IAMPrincipal
+	Managed map[string]*ManagedPolicy
+	Missing []string // managed policies whose document is not in the results
+}
+
+// Documents of AWS managed policies that matter for privilege escalation, used when the results don't have them
+var well_known_policies = map[string]string{
+	"arn:aws:iam::aws:policy/AdministratorAccess": `{"Statement":[{"Effect":"Allow","Action":"*","Resource":"*"}]}`,
+	"arn:aws:iam::aws:policy/IAMFullAccess":       `{"Statement":[{"Effect":"Allow","Action":["iam:*","organizations:DescribeAccount","organizations:DescribeOrganization","organizations:DescribeOrganizationalUnit","organizations:DescribePolicy","organizations:ListChildren","organizations:ListParents","organizations:ListPoliciesForTarget","organizations:ListRoots","organizations:ListPolicies","organizations:ListTargetsForPolicy"],"Resource":"*"}]}`,
+	"arn:aws:iam::aws:policy/PowerUserAccess":     `{"Statement":[{"Effect":"Allow","NotAction":["iam:*","organizations:*","account:*"],"Resource":"*"},{"Effect":"Allow","Action":["iam:CreateServiceLinkedRole","iam:DeleteServiceLinkedRole","iam:ListRoles","organizations:DescribeOrganization","account:ListRegions","account:GetAccountInformation"],"Resource":"*"}]}`,
+}
+
+// Stored response shapes of the iam api calls, policy documents are decoded JSON objects (or strings in older results)
+type iam_inline_policy struct {
+	PolicyName     string
+	PolicyDocument json.RawMessage
+}
+
+type iam_attached_policy struct {
+	PolicyArn  string
+	PolicyName string
+}
+
+type iam_boundary struct {
+	PermissionsBoundaryArn string
+}
+
+type iam_instance_profile struct {
+	InstanceProfileName string
+	Arn                 string
+	Roles               []struct{ RoleName string }
+}
+
+type iam_policy_version struct {
+	Document         json.RawMessage
+	VersionId        string
+	IsDefaultVersion bool
+}
+
+type iam_authorization_details struct {
+	UserDetailList []struct {
+		UserName                string
+		Arn                     string
+		UserPolicyList          []iam_inline_policy
+		AttachedManagedPolicies []iam_attached_policy
+		GroupList               []string
+		PermissionsBoundary     *iam_boundary
+	}
+	GroupDetailList []struct {
+		GroupName               string
+		Arn                     string
+		GroupPolicyList         []iam_inline_policy
+		AttachedManagedPolicies []iam_attached_policy
+	}
+	RoleDetailList []struct {
+		RoleName                 string
+		Arn                      string
+		AssumeRolePolicyDocument json.RawMessage
+		RolePolicyList           []iam_inline_policy
+		AttachedManagedPolicies  []iam_attached_policy
+		InstanceProfileList      []iam_instance_profile
+		PermissionsBoundary      *iam_boundary
+	}
+	Policies []struct {
+		PolicyName        string
+		Arn               string
+		DefaultVersionId  string
+		PolicyVersionList []iam_policy_version
+	}
+}
+
+// LoadIAMModel rebuilds the IAM model from global/iam.json: GetAccountAuthorizationDetails first,
+// the per principal api calls (ListUsers, ListAttachedUserPolicies, GetUserPolicy ...) fill what it misses
+func LoadIAMModel() (*IAMModel, error) {
+	results, err := servicemaster.LoadResults(utils.GLOBAL_REGION, "iam", false)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &IAMModel{
+		Users:   make(map[string]*IAMPrincipal),
+		Roles:   make(map[string]*IAMPrincipal),
+		Groups:  make(map[string]*IAMPrincipal),
+		Managed: make(map[string]*ManagedPolicy),
+	}
+	model.load_authorization_details(results)
+	model.load_principal_calls(results)
+	model.resolve_managed()
+
+	for _, principal := range model.principals() {
+		if parts := strings.Split(principal.ARN, ":"); len(parts) > 4 && parts[4] != "" {
+			model.Account = parts[4]
+			break
+		}
+	}
+	return model, nil
+}
+
+func (model *IAMModel) load_authorization_details(results map[string]json.RawMessage) {
+	var details iam_authorization_details
+	if err := json.Unmarshal(results["GetAccountAuthorizationDetails"], &details); err != nil {
+		return
+	}
+
+	for _, user := range details.UserDetailList {
+		principal := model.principal(PRINCIPAL_USER, user.UserName, user.Arn)
+		principal.Inline = append(principal.Inline, inline_policies(user.UserPolicyList, "user inline")...)
+		principal.AttachedPolicies = append(principal.AttachedPolicies, attached_arns(user.AttachedManagedPolicies)...)
+		principal.Groups = append(principal.Groups, user.GroupList...)
+		if user.PermissionsBoundary != nil {
+			principal.BoundaryARN = user.PermissionsBoundary.PermissionsBoundaryArn
+		}
+	}
+	for _, group := range details.GroupDetailList {
+		principal := model.principal(PRINCIPAL_GROUP, group.GroupName, group.Arn)
+		principal.Inline = append(principal.Inline, inline_policies(group.GroupPolicyList, "group "+group.GroupName+" inline")...)
+		principal.AttachedPolicies = append(principal.AttachedPolicies, attached_arns(group.AttachedManagedPolicies)...)
+	}
+	for _, role := range details.RoleDetailList {
+		principal := model.principal(PRINCIPAL_ROLE, role.RoleName, role.Arn)
+		principal.Trust = parse_document(role.AssumeRolePolicyDocument)
+		principal.Inline = append(principal.Inline, inline_policies(role.RolePolicyList, "role inline")...)
+		principal.AttachedPolicies = append(principal.AttachedPolicies, attached_arns(role.AttachedManagedPolicies)...)
+		for _, profile := range role.InstanceProfileList {
+			principal.InstanceProfiles = append(principal.InstanceProfiles, profile.Arn)
+		}
+		if role.PermissionsBoundary != nil {
+			principal.BoundaryARN = role.PermissionsBoundary.PermissionsBoundaryArn
+		}
+	}
+	for _, managed := range details.Policies {
+		entry := model.managed(managed.Arn)
+		entry.Name = managed.PolicyName
+		for _, version := range managed.PolicyVersionList {
+			document := parse_document(version.Document)
+			if document == nil {
+				continue
+			}
+			entry.Versions[version.VersionId] = document
+			if version.IsDefaultVersion || version.VersionId == managed.DefaultVersionId {
+				entry.Default = document
+			}
+		}
+	}
+}
+
+// load_principal_calls adds the principals and policies of the per principal api calls missing from GetAccountAuthorizationDetails
+func (model *IAMModel) load_principal_calls(results map[string]json.RawMessage) {
+	var users struct {
+		Users []struct {
+			UserName            string
+			Arn                 string
+			PermissionsBoundary *iam_boundary
+		}
+	}
+	if json.Unmarshal(results["ListUsers"], &users) == nil {
+		for _, user := range users.Users {
+			principal := model.principal(PRINCIPAL_USER, user.UserName, user.Arn)
+			if user.PermissionsBoundary != nil && principal.BoundaryARN == "" {
+				principal.BoundaryARN = user.PermissionsBoundary.PermissionsBoundaryArn
+			}
+		}
+	}
+
+	var roles struct {
+		Roles []struct {
+			RoleName                 string
+			Arn                      string
+			AssumeRolePolicyDocument json.RawMessage
+			PermissionsBoundary      *iam_boundary
+		}
+	}
+	if json.Unmarshal(results["ListRoles"], &roles) == nil {
+		for _, role := range roles.Roles {
+			principal := model.principal(PRINCIPAL_ROLE, role.RoleName, role.Arn)
+			if principal.Trust == nil {
+				principal.Trust = parse_document(role.AssumeRolePolicyDocument)
+			}
+			if role.PermissionsBoundary != nil && principal.BoundaryARN == "" {
+				principal.BoundaryARN = role.PermissionsBoundary.PermissionsBoundaryArn
+			}
+		}
+	}
+
+	var groups struct {
+		Groups []struct {
+			GroupName string
+			Arn       string
+		}
+	}
+	if json.Unmarshal(results["ListGroups"], &groups) == nil {
+		for _, group := range groups.Groups {
+			model.principal(PRINCIPAL_GROUP, group.GroupName, group.Arn)
+		}
+	}
+
+	var profiles struct{ InstanceProfiles []iam_instance_profile }
+	if json.Unmarshal(results["ListInstanceProfiles"], &profiles) == nil {
+		for _, profile := range profiles.InstanceProfiles {
+			for _, role := range profile.Roles {
+				if principal, ok := model.Roles[role.RoleName]; ok && !utils.Find(principal.InstanceProfiles, profile.Arn) {
+					principal.InstanceProfiles = append(principal.InstanceProfiles, profile.Arn)
+				}
+			}
+		}
+	}
+
+	for _, kind := range []struct {
+		principal_type string
+		collection     map[string]*IAMPrincipal
+		name_field     string
+		prefix         string
+	}{
+		{PRINCIPAL_USER, model.Users, "UserName", "User"},
+		{PRINCIPAL_ROLE, model.Roles, "RoleName", "Role"},
+		{PRINCIPAL_GROUP, model.Groups, "GroupName", "Group"},
+	} {
+		// ListAttachedXPolicies: [{Input: {XName}, Output: {AttachedPolicies}}]
+		var attached []struct {
+			Input  map[string]string
+			Output struct{ AttachedPolicies []iam_attached_policy }
+		}
+		if json.Unmarshal(results["ListAttached"+kind.prefix+"Policies"], &attached) == nil {
+			for _, record := range attached {
+				if principal, ok := kind.collection[record.Input[kind.name_field]]; ok {
+					for _, arn := range attached_arns(record.Output.AttachedPolicies) {
+						if !utils.Find(principal.AttachedPolicies, arn) {
+							principal.AttachedPolicies = append(principal.AttachedPolicies, arn)
+						}
+					}
+				}
+			}
+		}
+
+		// GetXPolicy: [{Input: {XName, PolicyName}, Output: {PolicyDocument}}]
+		var inline []struct {
+			Input  map[string]string
+			Output iam_inline_policy
+		}
+		if json.Unmarshal(results["Get"+kind.prefix+"Policy"], &inline) == nil {
+			for _, record := range inline {
+				principal, ok := kind.collection[record.Input[kind.name_field]]
+				if !ok || has_inline(principal, record.Input["PolicyName"]) {
+					continue
+				}
+				source := kind.principal_type + " inline"
+				if kind.principal_type == PRINCIPAL_GROUP {
+					source = "group " + principal.Name + " inline"
+				}
+				record.Output.PolicyName = record.Input["PolicyName"]
+				principal.Inline = append(principal.Inline, inline_policies([]iam_inline_policy{record.Output}, source)...)
+			}
+		}
+	}
+
+	var memberships []struct {
+		Input  struct{ UserName string }
+		Output struct{ Groups []struct{ GroupName string } }
+	}
+	if json.Unmarshal(results["ListGroupsForUser"], &memberships) == nil {
+		for _, record := range memberships {
+			if principal, ok := model.Users[record.Input.UserName]; ok {
+				for _, group := range record.Output.Groups {
+					if !utils.Find(principal.Groups, group.GroupName) {
+						principal.Groups = append(principal.Groups, group.GroupName)
+					}
+				}
+			}
+		}
+	}
+
+	var versions []struct {
+		Input  struct{ PolicyArn, VersionId string }
+		Output struct{ PolicyVersion iam_policy_version }
+	}
+	if json.Unmarshal(results["GetPolicyVersion"], &versions) == nil {
+		for _, record := range versions {
+			entry := model.managed(record.Input.PolicyArn)
+			if document := parse_document(record.Output.PolicyVersion.Document); document != nil && entry.Default == nil {
+				entry.Default = document
+				entry.Versions[record.Input.VersionId] = document
+			}
+		}
+	}
+}
+
+// resolve_managed fills the managed policies without document from the well known AWS policies and lists the missing ones
+func (model *IAMModel) resolve_managed() {
+	for _, principal := range model.principals() {
+		arns := principal.AttachedPolicies
+		if principal.BoundaryARN != "" {
+			arns = append(append([]string(nil), arns...), principal.BoundaryARN)
+		}
+		for _, arn := range arns {
+			entry := model.managed(arn)
+			if entry.Default != nil {
+				continue
+			}
+			if document, ok := well_known_policies[arn]; ok {
+				entry.Default, _ = policy.Parse(document)
+				continue
+			}
+			if !utils.Find(model.Missing, arn) {
+				model.Missing = append(model.Missing, arn)
+			}
+		}
+	}
+	sort.Strings(model.Missing)
+}
+
+func (model *IAMModel) principal(principal_type, name, arn string) *IAMPrincipal {
+	collection := model.Users
+	switch principal_type {
+	case PRINCIPAL_ROLE:
+		collection = model.Roles
+	case PRINCIPAL_GROUP:
+		collection = model.Groups
+	}
+	principal, ok := collection[name]
+	if !ok {
+		principal = &IAMPrincipal{Type: principal_type, Name: name, ARN: arn}
+		collection[name] = principal
+	}
+	return principal
+}
+
+func (model *IAMModel) managed(arn string) *ManagedPolicy {
+	entry, ok := model.Managed[arn]
+	if !ok {
+		entry = &ManagedPolicy{ARN: arn, Versions: make(map[string]*policy.Document)}
+		model.Managed[arn] = entry
+	}
+	return entry
+}
+
+// principals returns the users, roles and groups of the model sorted by ARN
+func (model *IAMModel) principals() []*IAMPrincipal {
+	var principals []*IAMPrincipal
+	for _, collection := range []map[string]*IAMPrincipal{model.Users, model.Roles, model.Groups} {
+		for _, principal := range collection {
+			principals = append(principals, principal)
+		}
+	}
+	sort.Slice(principals, func(i, j int) bool { return principals[i].ARN < principals[j].ARN })
+	return principals
+}
+
+// Lookup returns the principal of a caller identity
+func (model *IAMModel) Lookup(caller Principal) (*IAMPrincipal, bool) {
+	switch caller.Type {
+	case PRINCIPAL_USER:
+		principal, ok := model.Users[caller.Name]
+		return principal, ok
+	case PRINCIPAL_ROLE:
+		principal, ok := model.Roles[caller.Name]
+		return principal, ok
+	}
+	return nil, false
+}
+
+// Policies returns the identity policies of a principal (with the policies of the groups of a user) and its permissions boundary
+func (model *IAMModel) Policies(principal *IAMPrincipal) ([]policy.Policy, []policy.Policy) {
+	policies := append([]policy.Policy(nil), principal.Inline...)
+	policies = append(policies, model.attached(principal.AttachedPolicies, principal.Type+" attached")...)
+	for _, group_name := range principal.Groups {
+		if group, ok := model.Groups[group_name]; ok {
+			policies = append(policies, group.Inline...)
+			policies = append(policies, model.attached(group.AttachedPolicies, "group "+group_name+" attached")...)
+		}
+	}
+
+	var boundary []policy.Policy
+	if principal.BoundaryARN != "" {
+		boundary = model.attached([]string{principal.BoundaryARN}, "permissions boundary")
+		if len(boundary) == 0 {
+			boundary = []policy.Policy{unreadable_boundary(principal.BoundaryARN)}
+		}
+	}
+	return policies, boundary
+}
+
+// unreadable_boundary stands for a permissions boundary that could not be read:
+// it can only restrict, deny everything rather than report false positives
+func unreadable_boundary(arn string) policy.Policy {
+	deny_all, _ := policy.Parse(`{"Statement":[{"Effect":"Deny","Action":"*","Resource":"*"}]}`)
+	return policy.Policy{Name: arn, Source: "unreadable permissions boundary", Document: deny_all}
+}
+
+func (model *IAMModel) attached(arns []string, source string) []policy.Policy {
+	var policies []policy.Policy
+	for _, arn := range arns {
+		if entry, ok := model.Managed[arn]; ok && entry.Default != nil {
+			policies = append(policies, policy.Policy{Name: arn, Source: source, Document: entry.Default})
+		}
+	}
+	return policies
+}
+
+func inline_policies(entries []iam_inline_policy, source string) []policy.Policy {
+	var policies []policy.Policy
+	for _, entry := range entries {
+		if document := parse_document(entry.PolicyDocument); document != nil {
+			policies = append(policies, policy.Policy{Name: entry.PolicyName, Source: source, Document: document})
+		}
+	}
+	return policies
+}
+
+func attached_arns(attached []iam_attached_policy) []string {
+	var arns []string
+	for _, entry := range attached {
+		arns = append(arns, entry.PolicyArn)
+	}
+	return arns
+}
+
+func has_inline(principal *IAMPrincipal, policy_name string) bool {
+	for _, inline := range principal.Inline {
+		if inline.Name == policy_name {
+			return true
+		}
+	}
+	return false
+}
+
+// parse_document parses a stored policy document, a decoded JSON object or a (URL-encoded) JSON string
+func parse_document(raw json.RawMessage) *policy.Document {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var encoded string
+	if json.Unmarshal(raw, &encoded) == nil {
+		document, err := policy.Parse(encoded)
+		if err != nil {
+			return nil
+		}
+		return document
+	}
+	document, err := policy.ParseJSON(raw)
+	if err != nil {
+		return nil
+	}
+	return document
+}
@@ -0,0 +1,367 @@
+package analyze
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// INVENTORY_FILE holds a resource per line, written to utils.FILEPATH at the end of the enumeration
+const INVENTORY_FILE = "resources.jsonl"
+
+// Resource is an item of the inventory, extracted from the lists of the stored responses
+type Resource struct {
+	Type    string // service:kind, ec2:instance, iam:role, s3:bucket ...
+	ID      string
+	Arn     string            `json:",omitempty"`
+	Name    string            `json:",omitempty"`
+	Region  string            // region folder of the stored result, global for the global services
+	Account string            `json:",omitempty"` // from the ARN, or the identity of the enumeration
+	Tags    map[string]string `json:",omitempty"`
+	Service string
+	ApiCall string // stored api call the resource was found in
+}
+
+// Lists whose items only hold the list of the resources: DescribeInstances returns Reservations[].Instances[]
+var container_fields = map[string]string{"Reservations": "Instances"}
+
+// Kinds of the list fields that don't follow from their name
+var resource_kinds = map[string]string{"UserDetail": "User", "RoleDetail": "Role", "GroupDetail": "Group"}
+
+// Lists of a response that hold no resources
+var ignored_lists = []string{"Errors", "Failures", "Unsuccessful", "UnprocessedItems", "Warnings"}
+
+// Suffixes of the lists of plain identifiers: TableNames, QueueUrls, clusterArns
+var identifier_lists = regexp.MustCompile(`(Arns|ARNs|Urls|URLs|Names|Ids|IDs)$`)
+
+var tag_fields = []string{"Tags", "tags", "TagList", "TagSet", "TagsList"}
+
+// inventory collects the resources of a run, deduplicated by type, identifier and region
+type inventory struct {
+	account   string
+	resources map[string]*Resource
+}
+
+// ExtractResources walks the stored responses of every service and region and returns the inventory,
+// sorted by type, region and ID. The records of dependent api calls holding tags ( GetBucketTagging, ListTags ... )
+// add their tags to the resource named by their input.
+func ExtractResources() []Resource {
+	inv := &inventory{account: stored_account(), resources: make(map[string]*Resource)}
+	type tag_record struct {
+		region, service string
+		input           interface{}
+		tags            map[string]string
+	}
+	var tag_records []tag_record
+
+	for _, region := range servicemaster.StoredRegions() {
+		for _, svc := range servicemaster.StoredServices(region, false) {
+			results, err := servicemaster.LoadResults(region, svc, false)
+			if err != nil {
+				continue
+			}
+			// api calls in name order, a resource listed by several calls keeps the first one
+			apicalls := make([]string, 0, len(results))
+			for apicall := range results {
+				apicalls = append(apicalls, apicall)
+			}
+			sort.Strings(apicalls)
+
+			for _, apicall := range apicalls {
+				var value interface{}
+				if json.Unmarshal(results[apicall], &value) != nil {
+					continue
+				}
+				if records, ok := value.([]interface{}); ok {
+					for _, record := range records {
+						record, _ := record.(map[string]interface{})
+						if output, ok := record["Output"].(map[string]interface{}); ok {
+							if tags := tags_of(output); len(tags) > 0 {
+								tag_records = append(tag_records, tag_record{region, svc, record["Input"], tags})
+							}
+						}
+					}
+					continue
+				}
+				if object, ok := value.(map[string]interface{}); ok {
+					inv.collect(Resource{Service: svc, Region: region, ApiCall: apicall}, object)
+				}
+			}
+		}
+	}
+
+	for _, record := range tag_records {
+		inv.tag(record.region, record.service, record.input, record.tags)
+	}
+
+	resources := make([]Resource, 0, len(inv.resources))
+	for _, resource := range inv.resources {
+		resources = append(resources, *resource)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		a, b := resources[i], resources[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.ID < b.ID
+	})
+	return resources
+}
+
+// collect adds the items of the list fields of a response, location holds the service, region and api call
+func (inv *inventory) collect(location Resource, response map[string]interface{}) {
+	for _, field := range sorted_fields(response) {
+		items, ok := response[field].([]interface{})
+		if !ok || utils.Find(ignored_lists, field) || utils.Find(volatile_fields, field) {
+			continue
+		}
+		for _, item := range items {
+			switch item := item.(type) {
+			case string:
+				if !identifier_lists.MatchString(field) {
+					continue
+				}
+				if strings.HasPrefix(item, "arn:") {
+					inv.add(location, identifier_kind(field), map[string]interface{}{"Arn": item})
+				} else {
+					inv.add(location, identifier_kind(field), map[string]interface{}{"Id": item})
+				}
+			case map[string]interface{}:
+				if inner, ok := container_fields[field]; ok {
+					if nested, ok := item[inner].([]interface{}); ok {
+						inv.collect(location, map[string]interface{}{inner: nested})
+					}
+					continue
+				}
+				inv.add(location, singular(field), item)
+			}
+		}
+	}
+}
+
+// add records a resource of the kind, items without an identifier are not resources
+func (inv *inventory) add(location Resource, kind string, item map[string]interface{}) {
+	if mapped, ok := resource_kinds[kind]; ok {
+		kind = mapped
+	}
+	resource := location
+	resource.Type = location.Service + ":" + kebab_case(kind)
+	resource.Arn = string_field(item, []string{"Arn", "ARN", "arn", kind + "Arn", kind + "ARN"}, "Arn", "arn:")
+	resource.ID = string_field(item, []string{kind + "Id", "Id", "id", kind + "Identifier", "Identifier", kind + "Name", "Name", "name", "Key"}, "Id", "")
+	if resource.ID == "" {
+		resource.ID = resource.Arn
+	}
+	if resource.ID == "" {
+		return
+	}
+	resource.Tags = tags_of(item)
+	resource.Name = string_field(item, []string{kind + "Name", "Name", "name"}, "", "")
+	if resource.Name == "" {
+		resource.Name = resource.Tags["Name"]
+	}
+	resource.Account = arn_account(resource.Arn)
+	if resource.Account == "" {
+		resource.Account = inv.account
+	}
+
+	key := resource.Type + "|" + resource.Region + "|" + resource.ID
+	if known, ok := inv.resources[key]; ok {
+		known.Tags = merge_tags(known.Tags, resource.Tags)
+		if known.Arn == "" {
+			known.Arn = resource.Arn
+		}
+		return
+	}
+	inv.resources[key] = &resource
+}
+
+// tag adds the tags of a dependent api call record to the resource whose ID, ARN or name is a value of its input
+func (inv *inventory) tag(region, service string, input interface{}, tags map[string]string) {
+	object, ok := input.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, resource := range inv.resources {
+		if resource.Region != region || resource.Service != service {
+			continue
+		}
+		for _, value := range object {
+			if value, ok := value.(string); ok && value != "" && (value == resource.ID || value == resource.Arn || value == resource.Name) {
+				resource.Tags = merge_tags(resource.Tags, tags)
+				if resource.Name == "" {
+					resource.Name = resource.Tags["Name"]
+				}
+			}
+		}
+	}
+}
+
+// string_field returns the first non empty field of names, then any field with the suffix whose value has the prefix
+func string_field(item map[string]interface{}, names []string, suffix, prefix string) string {
+	for _, name := range names {
+		if value, ok := item[name].(string); ok && value != "" && strings.HasPrefix(value, prefix) {
+			return value
+		}
+	}
+	if suffix == "" {
+		return ""
+	}
+	for _, name := range sorted_fields(item) {
+		if value, ok := item[name].(string); ok && value != "" && strings.HasSuffix(name, suffix) && strings.HasPrefix(value, prefix) {
+			return value
+		}
+	}
+	return ""
+}
+
+// tags_of reads the tags of an item: [{"Key": k, "Value": v}] lists ( any case ) or {k: v} maps
+func tags_of(item map[string]interface{}) map[string]string {
+	tags := make(map[string]string)
+	for _, field := range tag_fields {
+		switch value := item[field].(type) {
+		case []interface{}:
+			for _, tag := range value {
+				tag, _ := tag.(map[string]interface{})
+				key := string_field(tag, []string{"Key", "key", "TagKey"}, "", "")
+				if key != "" {
+					tags[key] = string_field(tag, []string{"Value", "value", "TagValue"}, "", "")
+				}
+			}
+		case map[string]interface{}:
+			for key, tag_value := range value {
+				if tag_value, ok := tag_value.(string); ok {
+					tags[key] = tag_value
+				}
+			}
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+func merge_tags(tags, more map[string]string) map[string]string {
+	if len(more) == 0 {
+		return tags
+	}
+	if tags == nil {
+		tags = make(map[string]string)
+	}
+	for key, value := range more {
+		tags[key] = value
+	}
+	return tags
+}
+
+// arn_account returns the account field of an ARN, empty for the resources without account ( s3 buckets )
+func arn_account(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+	return parts[4]
+}
+
+// identifier_kind returns the kind of a list of identifiers: TableNames -> Table, clusterArns -> Cluster
+func identifier_kind(field string) string {
+	kind := identifier_lists.ReplaceAllString(field, "")
+	if kind == "" {
+		return "Resource"
+	}
+	return strings.ToUpper(kind[:1]) + kind[1:]
+}
+
+// kebab_case converts a kind to the type suffix: SecurityGroup -> security-group, DBInstance -> db-instance
+func kebab_case(kind string) string {
+	var result []rune
+	runes := []rune(kind)
+	for i, r := range runes {
+		upper := r >= 'A' && r <= 'Z'
+		if upper && i > 0 {
+			previous_lower := runes[i-1] >= 'a' && runes[i-1] <= 'z'
+			next_lower := i+1 < len(runes) && runes[i+1] >= 'a' && runes[i+1] <= 'z'
+			if previous_lower || (next_lower && runes[i-1] >= 'A' && runes[i-1] <= 'Z') {
+				result = append(result, '-')
+			}
+		}
+		result = append(result, r)
+	}
+	return strings.ToLower(string(result))
+}
+
+// SaveInventory writes the resources to INVENTORY_FILE, one JSON object per line
+func SaveInventory(resources []Resource) error {
+	file, err := os.Create(filepath.Join(utils.FILEPATH, INVENTORY_FILE))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, resource := range resources {
+		if err := encoder.Encode(resource); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// LoadInventory reads INVENTORY_FILE of the results directory
+func LoadInventory() ([]Resource, error) {
+	file, err := os.Open(filepath.Join(utils.FILEPATH, INVENTORY_FILE))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var resources []Resource
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var resource Resource
+		if err := json.Unmarshal(scanner.Bytes(), &resource); err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+	return resources, scanner.Err()
+}
+
+// InventoryFilter selects resources by type ( ec2:instance, or every type of a service: ec2 ), region and tag ( Key or Key=Value )
+type InventoryFilter struct {
+	Types   []string
+	Regions []string
+	Tags    []string
+}
+
+// Match reports whether the resource passes every criterion of the filter, each criterion matches any of its values
+func (filter InventoryFilter) Match(resource Resource) bool {
+	if len(filter.Types) > 0 && !utils.Find(filter.Types, resource.Type) && !utils.Find(filter.Types, resource.Service) {
+		return false
+	}
+	if len(filter.Regions) > 0 && !utils.Find(filter.Regions, resource.Region) {
+		return false
+	}
+	if len(filter.Tags) > 0 {
+		for _, tag := range filter.Tags {
+			key, value, with_value := strings.Cut(tag, "=")
+			if tag_value, ok := resource.Tags[key]; ok && (!with_value || tag_value == value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package analyze
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+func TestSingular(t *testing.T) {
+	tests := map[string]string{
+		"Functions":      "Function",
+		"Policies":       "Policy",
+		"Addresses":      "Address",
+		"Databases":      "Database",
+		"Buckets":        "Bucket",
+		"Boxes":          "Box",
+		"Branches":       "Branch",
+		"Batches":        "Batch",
+		"Stacks":         "Stack",
+		"UserDetailList": "UserDetail",
+		"Reservations":   "Reservation",
+		"Metadata":       "Metadata",
+	}
+	for field, kind := range tests {
+		if got := singular(field); got != kind {
+			t.Errorf("singular(%q) = %q, want %q", field, got, kind)
+		}
+	}
+}
+
+func TestKebabCase(t *testing.T) {
+	tests := map[string]string{
+		"Instance":          "instance",
+		"SecurityGroup":     "security-group",
+		"DBInstance":        "db-instance",
+		"DBClusterSnapshot": "db-cluster-snapshot",
+		"VpcEndpoint":       "vpc-endpoint",
+		"KMSKey":            "kms-key",
+		"EC2":               "ec2",
+		"cluster":           "cluster",
+	}
+	for kind, kebab := range tests {
+		if got := kebab_case(kind); got != kebab {
+			t.Errorf("kebab_case(%q) = %q, want %q", kind, got, kebab)
+		}
+	}
+}
+
+func TestIdentifierKind(t *testing.T) {
+	tests := map[string]string{
+		"TableNames":  "Table",
+		"QueueUrls":   "Queue",
+		"clusterArns": "Cluster",
+		"StreamNames": "Stream",
+		"Arns":        "Resource",
+	}
+	for field, kind := range tests {
+		if got := identifier_kind(field); got != kind {
+			t.Errorf("identifier_kind(%q) = %q, want %q", field, got, kind)
+		}
+	}
+}
+
+// store_results writes the responses of a service in the layout of save_result_to_file
+func store_results(t *testing.T, region, svc string, responses map[string]string) {
+	t.Helper()
+	var packed []string
+	for apicall, response := range responses {
+		packed = append(packed, `{"`+apicall+`": `+response+`}`)
+	}
+	data, _ := json.Marshal(map[string][]string{svc: packed})
+	if err := os.MkdirAll(utils.ResultPath(region), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(utils.ResultPath(region), svc+".json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractResources(t *testing.T) {
+	current := utils.FILEPATH
+	utils.SetOutputDir(t.TempDir())
+	defer utils.SetOutputDir(current)
+
+	store_results(t, utils.GLOBAL_REGION, "sts", map[string]string{"GetCallerIdentity": `{"Account": "111111111111"}`})
+	store_results(t, "eu-west-1", "ec2", map[string]string{
+		"DescribeInstances":      `{"Reservations": [{"Instances": [{"InstanceId": "i-1", "Tags": [{"Key": "Name", "Value": "web"}, {"Key": "env", "Value": "prod"}]}, {"InstanceId": "i-2"}]}]}`,
+		"DescribeSecurityGroups": `{"SecurityGroups": [{"GroupId": "sg-1", "GroupName": "default"}], "ResultMetadata": {}}`,
+	})
+	store_results(t, utils.GLOBAL_REGION, "iam", map[string]string{
+		"ListRoles":                      `{"Roles": [{"RoleName": "admin", "RoleId": "AROA1", "Arn": "arn:aws:iam::222222222222:role/admin"}], "IsTruncated": false}`,
+		"GetAccountAuthorizationDetails": `{"RoleDetailList": [{"RoleName": "admin", "RoleId": "AROA1", "Arn": "arn:aws:iam::222222222222:role/admin", "Tags": [{"Key": "team", "Value": "sec"}]}]}`,
+	})
+	store_results(t, "eu-west-1", "dynamodb", map[string]string{"ListTables": `{"TableNames": ["orders"], "Errors": [{"Id": "not-a-resource"}]}`})
+	store_results(t, utils.GLOBAL_REGION, "s3", map[string]string{
+		"ListBuckets":      `{"Buckets": [{"Name": "logs"}, {"Name": "data"}]}`,
+		"GetBucketTagging": `[{"Input": {"Bucket": "logs"}, "Output": {"TagSet": [{"Key": "owner", "Value": "ops"}]}}, {"Input": {"Bucket": "data"}, "Error": {"Class": "access_denied"}}]`,
+	})
+
+	resources := ExtractResources()
+	found := make(map[string]Resource)
+	for _, resource := range resources {
+		found[resource.Type+" "+resource.ID] = resource
+	}
+	want := []string{
+		"dynamodb:table orders",
+		"ec2:instance i-1",
+		"ec2:instance i-2",
+		"ec2:security-group sg-1",
+		"iam:role AROA1",
+		"s3:bucket data",
+		"s3:bucket logs",
+	}
+	var keys []string
+	for _, resource := range resources {
+		keys = append(keys, resource.Type+" "+resource.ID)
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("resources %v, want %v", keys, want)
+	}
+
+	instance := found["ec2:instance i-1"]
+	if instance.Name != "web" || instance.Tags["env"] != "prod" || instance.Region != "eu-west-1" || instance.Account != "111111111111" || instance.ApiCall != "DescribeInstances" {
+		t.Errorf("instance %+v", instance)
+	}
+	// the role listed by two calls keeps the first one and merges the tags
+	role := found["iam:role AROA1"]
+	if role.Account != "222222222222" || role.Name != "admin" || role.Tags["team"] != "sec" || role.ApiCall != "GetAccountAuthorizationDetails" {
+		t.Errorf("role %+v", role)
+	}
+	if logs := found["s3:bucket logs"]; logs.Tags["owner"] != "ops" {
+		t.Errorf("bucket logs %+v", logs)
+	}
+	if data := found["s3:bucket data"]; len(data.Tags) != 0 {
+		t.Errorf("bucket data %+v", data)
+	}
+}
+
+func TestInventoryFilter(t *testing.T) {
+	resource := Resource{Type: "ec2:instance", Service: "ec2", Region: "eu-west-1", Tags: map[string]string{"env": "prod"}}
+	tests := []struct {
+		name   string
+		filter InventoryFilter
+		match  bool
+	}{
+		{"no criterion", InventoryFilter{}, true},
+		{"type", InventoryFilter{Types: []string{"ec2:instance"}}, true},
+		{"service", InventoryFilter{Types: []string{"ec2"}}, true},
+		{"other type", InventoryFilter{Types: []string{"ec2:volume"}}, false},
+		{"region", InventoryFilter{Regions: []string{"us-east-1", "eu-west-1"}}, true},
+		{"other region", InventoryFilter{Regions: []string{"us-east-1"}}, false},
+		{"tag key", InventoryFilter{Tags: []string{"env"}}, true},
+		{"tag value", InventoryFilter{Tags: []string{"env=prod"}}, true},
+		{"other tag value", InventoryFilter{Tags: []string{"env=dev"}}, false},
+		{"any tag", InventoryFilter{Tags: []string{"owner", "env=prod"}}, true},
+		{"every criterion", InventoryFilter{Types: []string{"ec2"}, Regions: []string{"us-east-1"}, Tags: []string{"env"}}, false},
+	}
+	for _, test := range tests {
+		if got := test.filter.Match(resource); got != test.match {
+			t.Errorf("%s: match %v, want %v", test.name, got, test.match)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/policy"
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// PRIVESC_FILE is written to utils.FILEPATH by analyze privesc
+const PRIVESC_FILE = "analyze-privesc.json"
+
+// privesc_max_depth bounds the number of pivots of a path
+const privesc_max_depth = 4
+
+// PrivescStep is a single escalation primitive from a principal to another one (or to more privileges for itself)
+type PrivescStep struct {
+	Technique   string
+	From        string
+	To          string
+	Actions     []string
+	Resource    string
+	Description string
+	Conditional bool `json:",omitempty"` // an action or a trust statement depends on conditions
+}
+
+// PrivescPath is a chain of steps from the analyzed principal to a principal with higher privileges
+type PrivescPath struct {
+	Target      string
+	Admin       bool // the target has full administrative access
+	Conditional bool `json:",omitempty"`
+	Steps       []PrivescStep
+}
+
+// PrivescReport is the result of analyze privesc, saved to PRIVESC_FILE
+type PrivescReport struct {
+	Principal string
+	Admin     bool // the principal already has full administrative access
+	Paths     []PrivescPath
+	Findings  []Finding
+	Missing   []string `json:",omitempty"` // managed policies without document, left out of the analysis
+}
+
+// Techniques that need iam:PassRole on a role trusting the service principal
+type passrole_technique struct {
+	name        string
+	service     string
+	actions     []string
+	description string
+}
+
+var passrole_techniques = []passrole_technique{
+	{"passrole-lambda", "lambda.amazonaws.com", []string{"lambda:CreateFunction", "lambda:InvokeFunction"}, "create a Lambda function with the role and invoke it"},
+	{"passrole-ec2", "ec2.amazonaws.com", []string{"ec2:RunInstances"}, "run an EC2 instance with an instance profile of the role"},
+	{"passrole-cloudformation", "cloudformation.amazonaws.com", []string{"cloudformation:CreateStack"}, "create a CloudFormation stack deployed with the role"},
+	{"passrole-glue", "glue.amazonaws.com", []string{"glue:CreateDevEndpoint"}, "create a Glue development endpoint with the role"},
+	{"passrole-codebuild", "codebuild.amazonaws.com", []string{"codebuild:CreateProject", "codebuild:StartBuild"}, "create and start a CodeBuild project with the role"},
+	{"passrole-sagemaker", "sagemaker.amazonaws.com", []string{"sagemaker:CreateNotebookInstance", "sagemaker:CreatePresignedNotebookInstanceUrl"}, "create a SageMaker notebook with the role and open it"},
+	{"passrole-datapipeline", "datapipeline.amazonaws.com", []string{"datapipeline:CreatePipeline", "datapipeline:PutPipelineDefinition", "datapipeline:ActivatePipeline"}, "create a Data Pipeline running with the role"},
+}
+
+// lambda_function is a function of the stored lambda:ListFunctions responses
+type lambda_function struct {
+	FunctionName string
+	FunctionArn  string
+	Role         string
+}
+
+// privesc_analyzer evaluates the escalation primitives of the principals of the model
+type privesc_analyzer struct {
+	model     *IAMModel
+	functions []lambda_function
+	results   map[string]map[string]policy.Result // principal ARN -> action -> evaluation
+}
+
+// AnalyzePrivesc searches the stored IAM data for escalation paths from the principal to higher privileges
+func AnalyzePrivesc(model *IAMModel, start *IAMPrincipal) *PrivescReport {
+	analyzer := &privesc_analyzer{model: model, functions: load_lambda_functions(), results: make(map[string]map[string]policy.Result)}
+	report := &PrivescReport{Principal: start.ARN, Admin: analyzer.is_admin(start), Missing: model.Missing}
+	if report.Admin {
+		return report
+	}
+
+	type queued struct {
+		principal *IAMPrincipal
+		steps     []PrivescStep
+	}
+	visited := map[string]bool{start.ARN: true}
+	queue := []queued{{principal: start}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, step := range analyzer.escalations(current.principal) {
+			steps := append(append([]PrivescStep(nil), current.steps...), step)
+			path := PrivescPath{Target: step.To, Steps: steps}
+			for _, s := range steps {
+				path.Conditional = path.Conditional || s.Conditional
+			}
+
+			target := analyzer.lookup_arn(step.To)
+			if step.To == current.principal.ARN {
+				// the principal modifies its own policies, any policy can be granted
+				path.Admin = true
+				report.Paths = append(report.Paths, path)
+				continue
+			}
+			if target == nil || visited[step.To] {
+				continue
+			}
+			visited[step.To] = true
+			path.Admin = analyzer.is_admin(target)
+			report.Paths = append(report.Paths, path)
+			if !path.Admin && len(steps) < privesc_max_depth {
+				queue = append(queue, queued{principal: target, steps: steps})
+			}
+		}
+	}
+
+	// full admin first, then the shortest and unconditional paths
+	sort.SliceStable(report.Paths, func(i, j int) bool {
+		a, b := report.Paths[i], report.Paths[j]
+		if a.Admin != b.Admin {
+			return a.Admin
+		}
+		if len(a.Steps) != len(b.Steps) {
+			return len(a.Steps) < len(b.Steps)
+		}
+		return !a.Conditional && b.Conditional
+	})
+	for _, path := range report.Paths {
+		report.Findings = append(report.Findings, path.finding(report.Principal))
+	}
+	return report
+}
+
+// finding describes the path as a finding of the principal: critical to admin, medium to another principal,
+// a severity lower when it depends on conditions
+func (path PrivescPath) finding(principal string) Finding {
+	finding := Finding{
+		ID:       "privesc-pivot",
+		Title:    "Privilege escalation to another principal",
+		Severity: SEVERITY_MEDIUM,
+		Resource: principal,
+		Service:  "iam",
+		Region:   utils.GLOBAL_REGION,
+	}
+	if path.Admin {
+		finding.ID, finding.Title, finding.Severity = "privesc-admin", "Privilege escalation to full administrative access", SEVERITY_CRITICAL
+	}
+	if path.Conditional {
+		finding.Severity = map[string]string{SEVERITY_CRITICAL: SEVERITY_HIGH, SEVERITY_MEDIUM: SEVERITY_LOW}[finding.Severity]
+	}
+
+	var techniques, actions []string
+	for _, step := range path.Steps {
+		techniques = append(techniques, step.Technique)
+		for _, action := range step.Actions {
+			if !utils.Find(actions, action) {
+				actions = append(actions, action)
+			}
+		}
+	}
+	finding.Description = fmt.Sprintf("%s can reach %s: %s", principal, path.Target, strings.Join(techniques, ", then "))
+	finding.Remediation = "Remove or scope down " + strings.Join(actions, ", ") + " in the policies granting them, or tighten the trust policies on the path"
+	return finding
+}
+
+// escalations lists the primitives available to a principal
+func (analyzer *privesc_analyzer) escalations(principal *IAMPrincipal) []PrivescStep {
+	var steps []PrivescStep
+	steps = append(steps, analyzer.self_escalations(principal)...)
+	steps = append(steps, analyzer.user_takeovers(principal)...)
+	steps = append(steps, analyzer.role_takeovers(principal)...)
+	return steps
+}
+
+// self_escalations are the primitives that grant more policies to the principal itself
+func (analyzer *privesc_analyzer) self_escalations(principal *IAMPrincipal) []PrivescStep {
+	var steps []PrivescStep
+	add := func(technique, description, resource string, actions ...string) {
+		if ok, conditional := analyzer.can(principal, resource, actions...); ok {
+			steps = append(steps, PrivescStep{Technique: technique, From: principal.ARN, To: principal.ARN, Actions: actions, Resource: resource, Description: description, Conditional: conditional})
+		}
+	}
+
+	// managed policies attached to the principal or its groups
+	attached := append([]string(nil), principal.AttachedPolicies...)
+	targets := []*IAMPrincipal{principal}
+	for _, group_name := range principal.Groups {
+		if group, ok := analyzer.model.Groups[group_name]; ok {
+			attached = append(attached, group.AttachedPolicies...)
+			targets = append(targets, group)
+		}
+	}
+	for _, arn := range attached {
+		if strings.HasPrefix(arn, "arn:aws:iam::aws:") {
+			continue // AWS managed policies can't be modified
+		}
+		add("iam-create-policy-version", "create a new default version of the attached policy "+arn, arn, "iam:CreatePolicyVersion")
+		if analyzer.has_admin_version(arn) {
+			add("iam-set-default-policy-version", "make an administrative non-default version of "+arn+" the default one", arn, "iam:SetDefaultPolicyVersion")
+		}
+	}
+
+	for _, target := range targets {
+		switch target.Type {
+		case PRINCIPAL_USER:
+			add("iam-attach-policy", "attach AdministratorAccess to the user", target.ARN, "iam:AttachUserPolicy")
+			add("iam-put-inline-policy", "put an administrative inline policy on the user", target.ARN, "iam:PutUserPolicy")
+		case PRINCIPAL_ROLE:
+			add("iam-attach-policy", "attach AdministratorAccess to the role", target.ARN, "iam:AttachRolePolicy")
+			add("iam-put-inline-policy", "put an administrative inline policy on the role", target.ARN, "iam:PutRolePolicy")
+		case PRINCIPAL_GROUP:
+			add("iam-attach-policy", "attach AdministratorAccess to the group "+target.Name, target.ARN, "iam:AttachGroupPolicy")
+			add("iam-put-inline-policy", "put an administrative inline policy on the group "+target.Name, target.ARN, "iam:PutGroupPolicy")
+		}
+	}
+	return steps
+}
+
+// user_takeovers are the primitives that give access to other users or to the policies of other groups
+func (analyzer *privesc_analyzer) user_takeovers(principal *IAMPrincipal) []PrivescStep {
+	var steps []PrivescStep
+	add := func(technique, description string, target *IAMPrincipal, actions ...string) {
+		if ok, conditional := analyzer.can(principal, target.ARN, actions...); ok {
+			steps = append(steps, PrivescStep{Technique: technique, From: principal.ARN, To: target.ARN, Actions: actions, Resource: target.ARN, Description: description, Conditional: conditional})
+		}
+	}
+
+	for _, user := range sorted_principals(analyzer.model.Users) {
+		if user.ARN == principal.ARN {
+			continue
+		}
+		add("iam-create-access-key", "create an access key of the user "+user.Name, user, "iam:CreateAccessKey")
+		add("iam-create-login-profile", "set a console password for the user "+user.Name, user, "iam:CreateLoginProfile")
+		add("iam-update-login-profile", "change the console password of the user "+user.Name, user, "iam:UpdateLoginProfile")
+	}
+
+	if principal.Type == PRINCIPAL_USER {
+		for _, group := range sorted_principals(analyzer.model.Groups) {
+			if !utils.Find(principal.Groups, group.Name) {
+				add("iam-add-user-to-group", "join the group "+group.Name, group, "iam:AddUserToGroup")
+			}
+		}
+	}
+	return steps
+}
+
+// role_takeovers are the primitives that give the credentials (or the permissions) of a role
+func (analyzer *privesc_analyzer) role_takeovers(principal *IAMPrincipal) []PrivescStep {
+	var steps []PrivescStep
+	for _, role := range sorted_principals(analyzer.model.Roles) {
+		if role.ARN == principal.ARN {
+			continue
+		}
+
+		// sts:AssumeRole: the trust policy has to trust the principal, naming it explicitly is enough in the same account
+		trust := policy.TrustsAWSPrincipal(role.Trust, "sts:AssumeRole", principal.ARN, analyzer.model.Account)
+		if trust.Trusted {
+			ok, conditional := trust.Explicit, false
+			if !ok {
+				ok, conditional = analyzer.can(principal, role.ARN, "sts:AssumeRole")
+			}
+			if ok {
+				description := "assume the role, its trust policy (" + trust.Statement + ") trusts the principal"
+				if !trust.Explicit {
+					description = "assume the role, its trust policy (" + trust.Statement + ") trusts the account or everyone"
+				}
+				steps = append(steps, PrivescStep{Technique: "sts-assume-role", From: principal.ARN, To: role.ARN, Actions: []string{"sts:AssumeRole"}, Resource: role.ARN, Description: description, Conditional: conditional || trust.Conditional})
+			}
+		}
+
+		if ok, conditional := analyzer.can(principal, role.ARN, "iam:UpdateAssumeRolePolicy"); ok {
+			steps = append(steps, PrivescStep{Technique: "iam-update-assume-role-policy", From: principal.ARN, To: role.ARN, Actions: []string{"iam:UpdateAssumeRolePolicy", "sts:AssumeRole"}, Resource: role.ARN, Description: "rewrite the trust policy of the role to trust the principal and assume it", Conditional: conditional})
+		}
+
+		for _, technique := range passrole_techniques {
+			trust := policy.TrustsService(role.Trust, "sts:AssumeRole", technique.service)
+			if !trust.Trusted {
+				continue
+			}
+			if technique.service == "ec2.amazonaws.com" && len(role.InstanceProfiles) == 0 {
+				continue
+			}
+			pass, pass_conditional := analyzer.can(principal, role.ARN, "iam:PassRole")
+			if !pass {
+				continue
+			}
+			if ok, conditional := analyzer.can(principal, "*", technique.actions...); ok {
+				steps = append(steps, PrivescStep{Technique: technique.name, From: principal.ARN, To: role.ARN, Actions: append([]string{"iam:PassRole"}, technique.actions...), Resource: role.ARN, Description: technique.description, Conditional: pass_conditional || conditional || trust.Conditional})
+			}
+		}
+
+		for _, function := range analyzer.functions {
+			if function.Role != role.ARN {
+				continue
+			}
+			if ok, conditional := analyzer.can(principal, function.FunctionArn, "lambda:UpdateFunctionCode"); ok {
+				steps = append(steps, PrivescStep{Technique: "lambda-update-function-code", From: principal.ARN, To: role.ARN, Actions: []string{"lambda:UpdateFunctionCode"}, Resource: function.FunctionArn, Description: "replace the code of the function " + function.FunctionName + " running with the role", Conditional: conditional})
+			}
+		}
+	}
+	return steps
+}
+
+// can reports whether the principal is allowed every action on the resource ("*" = on any resource),
+// conditional is true when one of the decisions depends on conditions
+func (analyzer *privesc_analyzer) can(principal *IAMPrincipal, resource string, actions ...string) (bool, bool) {
+	conditional := false
+	for _, action := range actions {
+		result := analyzer.evaluate(principal, action)
+		switch {
+		case result.Decision == policy.DECISION_UNKNOWN:
+			conditional = true
+		case resource == "*" && result.Decision == policy.DECISION_ALLOWED:
+		case result.Granted(resource):
+		default:
+			return false, false
+		}
+	}
+	return true, conditional
+}
+
+// evaluate evaluates an action for a principal, the results are cached per principal
+func (analyzer *privesc_analyzer) evaluate(principal *IAMPrincipal, action string) policy.Result {
+	results, ok := analyzer.results[principal.ARN]
+	if !ok {
+		results = make(map[string]policy.Result)
+		analyzer.results[principal.ARN] = results
+	}
+	if result, ok := results[action]; ok {
+		return result
+	}
+	policies, boundary := analyzer.model.Policies(principal)
+	results[action] = policy.Evaluate(action, policies, boundary)
+	return results[action]
+}
+
+// is_admin reports whether the principal is allowed every action (NotAction grants like PowerUserAccess are not admin)
+func (analyzer *privesc_analyzer) is_admin(principal *IAMPrincipal) bool {
+	return analyzer.evaluate(principal, "*").Granted("*") && analyzer.evaluate(principal, "iam:CreateUser").Granted("*")
+}
+
+// has_admin_version reports whether a non-default version of a managed policy allows every action
+func (analyzer *privesc_analyzer) has_admin_version(arn string) bool {
+	managed, ok := analyzer.model.Managed[arn]
+	if !ok {
+		return false
+	}
+	for _, document := range managed.Versions {
+		if document == managed.Default {
+			continue
+		}
+		versions := []policy.Policy{{Name: arn, Document: document}}
+		if policy.Evaluate("*", versions, nil).Granted("*") {
+			return true
+		}
+	}
+	return false
+}
+
+func (analyzer *privesc_analyzer) lookup_arn(arn string) *IAMPrincipal {
+	for _, collection := range []map[string]*IAMPrincipal{analyzer.model.Users, analyzer.model.Roles, analyzer.model.Groups} {
+		for _, principal := range collection {
+			if principal.ARN == arn {
+				return principal
+			}
+		}
+	}
+	return nil
+}
+
+// load_lambda_functions reads the functions of the stored lambda:ListFunctions responses of every region
+func load_lambda_functions() []lambda_function {
+	var functions []lambda_function
+	for _, region := range servicemaster.StoredRegions() {
+		results, err := servicemaster.LoadResults(region, "lambda", false)
+		if err != nil {
+			continue
+		}
+		var listed struct{ Functions []lambda_function }
+		if json.Unmarshal(results["ListFunctions"], &listed) == nil {
+			functions = append(functions, listed.Functions...)
+		}
+	}
+	return functions
+}
+
+func sorted_principals(collection map[string]*IAMPrincipal) []*IAMPrincipal {
+	principals := make([]*IAMPrincipal, 0, len(collection))
+	for _, principal := range collection {
+		principals = append(principals, principal)
+	}
+	sort.Slice(principals, func(i, j int) bool { return principals[i].ARN < principals[j].ARN })
+	return principals
+}
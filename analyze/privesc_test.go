@@ -0,0 +1,403 @@
+package analyze
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/threatroute66/aws-enumerator/policy"
+)
+
+const test_account = "111111111111"
+
+const (
+	allow_all     = `{"Effect": "Allow", "Action": "*", "Resource": "*"}`
+	lambda_trust  = `{"Statement": {"Effect": "Allow", "Principal": {"Service": "lambda.amazonaws.com"}, "Action": "sts:AssumeRole"}}`
+	ec2_trust     = `{"Statement": {"Effect": "Allow", "Principal": {"Service": "ec2.amazonaws.com"}, "Action": "sts:AssumeRole"}}`
+	account_trust = `{"Statement": {"Effect": "Allow", "Principal": {"AWS": "arn:aws:iam::111111111111:root"}, "Action": "sts:AssumeRole"}}`
+)
+
+func test_document(t *testing.T, statements ...string) *policy.Document {
+	t.Helper()
+	document, err := policy.Parse(`{"Statement": [` + strings.Join(statements, ",") + `]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return document
+}
+
+func test_arn(principal_type, name string) string {
+	return "arn:aws:iam::" + test_account + ":" + principal_type + "/" + name
+}
+
+// test_principal builds a principal with an inline policy holding the statements
+func test_principal(t *testing.T, principal_type, name string, statements ...string) *IAMPrincipal {
+	principal := &IAMPrincipal{Type: principal_type, Name: name, ARN: test_arn(principal_type, name)}
+	if len(statements) > 0 {
+		principal.Inline = []policy.Policy{{Name: name + "-inline", Source: principal_type + " inline", Document: test_document(t, statements...)}}
+	}
+	return principal
+}
+
+func test_role(t *testing.T, name, trust string, statements ...string) *IAMPrincipal {
+	role := test_principal(t, PRINCIPAL_ROLE, name, statements...)
+	document, err := policy.Parse(trust)
+	if err != nil {
+		t.Fatal(err)
+	}
+	role.Trust = document
+	return role
+}
+
+func test_iam_model(principals ...*IAMPrincipal) *IAMModel {
+	model := &IAMModel{
+		Account: test_account,
+		Users:   make(map[string]*IAMPrincipal),
+		Roles:   make(map[string]*IAMPrincipal),
+		Groups:  make(map[string]*IAMPrincipal),
+		Managed: make(map[string]*ManagedPolicy),
+	}
+	for _, principal := range principals {
+		switch principal.Type {
+		case PRINCIPAL_USER:
+			model.Users[principal.Name] = principal
+		case PRINCIPAL_ROLE:
+			model.Roles[principal.Name] = principal
+		case PRINCIPAL_GROUP:
+			model.Groups[principal.Name] = principal
+		}
+	}
+	return model
+}
+
+// describe_paths summarizes the paths as "technique > technique => target", sorted
+func describe_paths(paths []PrivescPath) []string {
+	var described []string
+	for _, path := range paths {
+		var techniques []string
+		for _, step := range path.Steps {
+			techniques = append(techniques, step.Technique)
+		}
+		description := strings.Join(techniques, " > ") + " => " + strings.TrimPrefix(path.Target, "arn:aws:iam::"+test_account+":")
+		if path.Admin {
+			description += " (admin)"
+		}
+		if path.Conditional {
+			description += " (conditional)"
+		}
+		described = append(described, description)
+	}
+	sort.Strings(described)
+	return described
+}
+
+func TestAnalyzePrivesc(t *testing.T) {
+	use_run_directory(t)
+	customer_policy := "arn:aws:iam::" + test_account + ":policy/dev"
+	tests := []struct {
+		name  string
+		model func() *IAMModel
+		paths []string
+	}{
+		{
+			name: "passrole to a lambda function",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev",
+						`{"Effect": "Allow", "Action": "iam:PassRole", "Resource": "`+test_arn("role", "deploy")+`"}`,
+						`{"Effect": "Allow", "Action": ["lambda:CreateFunction", "lambda:InvokeFunction"], "Resource": "*"}`),
+					test_role(t, "deploy", lambda_trust, allow_all),
+				)
+			},
+			paths: []string{"passrole-lambda => role/deploy (admin)"},
+		},
+		{
+			name: "lambda without passrole",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": "lambda:*", "Resource": "*"}`),
+					test_role(t, "deploy", lambda_trust, allow_all),
+				)
+			},
+		},
+		{
+			name: "passrole on another role",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev",
+						`{"Effect": "Allow", "Action": "iam:PassRole", "Resource": "`+test_arn("role", "other")+`"}`,
+						`{"Effect": "Allow", "Action": "lambda:*", "Resource": "*"}`),
+					test_role(t, "deploy", lambda_trust, allow_all),
+				)
+			},
+		},
+		{
+			name: "passrole to a role trusting another service",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": ["iam:PassRole", "lambda:*"], "Resource": "*"}`),
+					test_role(t, "web", ec2_trust, allow_all),
+				)
+			},
+		},
+		{
+			name: "passrole to ec2 without instance profile",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": ["iam:PassRole", "ec2:RunInstances"], "Resource": "*"}`),
+					test_role(t, "web", ec2_trust, allow_all),
+				)
+			},
+		},
+		{
+			name: "passrole to ec2",
+			model: func() *IAMModel {
+				role := test_role(t, "web", ec2_trust, allow_all)
+				role.InstanceProfiles = []string{"arn:aws:iam::" + test_account + ":instance-profile/web"}
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": ["iam:PassRole", "ec2:RunInstances"], "Resource": "*"}`),
+					role,
+				)
+			},
+			paths: []string{"passrole-ec2 => role/web (admin)"},
+		},
+		{
+			name: "new version of an attached customer policy",
+			model: func() *IAMModel {
+				user := test_principal(t, PRINCIPAL_USER, "dev")
+				user.AttachedPolicies = []string{customer_policy, "arn:aws:iam::aws:policy/ReadOnlyAccess"}
+				model := test_iam_model(user)
+				model.Managed[customer_policy] = &ManagedPolicy{ARN: customer_policy, Default: test_document(t, `{"Effect": "Allow", "Action": "iam:CreatePolicyVersion", "Resource": "*"}`)}
+				return model
+			},
+			paths: []string{"iam-create-policy-version => user/dev (admin)"},
+		},
+		{
+			name: "administrative version of an attached policy",
+			model: func() *IAMModel {
+				user := test_principal(t, PRINCIPAL_USER, "dev")
+				user.AttachedPolicies = []string{customer_policy}
+				model := test_iam_model(user)
+				current := test_document(t, `{"Effect": "Allow", "Action": "iam:SetDefaultPolicyVersion", "Resource": "*"}`)
+				model.Managed[customer_policy] = &ManagedPolicy{ARN: customer_policy, Default: current, Versions: map[string]*policy.Document{"v1": test_document(t, allow_all), "v2": current}}
+				return model
+			},
+			paths: []string{"iam-set-default-policy-version => user/dev (admin)"},
+		},
+		{
+			name: "attach a policy to itself",
+			model: func() *IAMModel {
+				return test_iam_model(test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": "iam:AttachUserPolicy", "Resource": "`+test_arn("user", "dev")+`"}`))
+			},
+			paths: []string{"iam-attach-policy => user/dev (admin)"},
+		},
+		{
+			name: "attach a policy to another user",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": "iam:AttachUserPolicy", "Resource": "`+test_arn("user", "ops")+`"}`),
+					test_principal(t, PRINCIPAL_USER, "ops"),
+				)
+			},
+		},
+		{
+			name: "attach a policy to its group",
+			model: func() *IAMModel {
+				user := test_principal(t, PRINCIPAL_USER, "dev")
+				user.Groups = []string{"devs"}
+				return test_iam_model(user, test_principal(t, PRINCIPAL_GROUP, "devs", `{"Effect": "Allow", "Action": "iam:AttachGroupPolicy", "Resource": "`+test_arn("group", "devs")+`"}`))
+			},
+			paths: []string{"iam-attach-policy => user/dev (admin)"},
+		},
+		{
+			name: "access key of an administrator",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": "iam:CreateAccessKey", "Resource": "`+test_arn("user", "ops")+`"}`),
+					test_principal(t, PRINCIPAL_USER, "ops", allow_all),
+					test_principal(t, PRINCIPAL_USER, "audit", allow_all),
+				)
+			},
+			paths: []string{"iam-create-access-key => user/ops (admin)"},
+		},
+		{
+			name: "assume a role trusting the account",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": "sts:AssumeRole", "Resource": "*"}`),
+					test_role(t, "admin", account_trust, allow_all),
+				)
+			},
+			paths: []string{"sts-assume-role => role/admin (admin)"},
+		},
+		{
+			name: "role trusting the account without sts:AssumeRole",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": "s3:*", "Resource": "*"}`),
+					test_role(t, "admin", account_trust, allow_all),
+				)
+			},
+		},
+		{
+			name: "role trusting the principal",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev"),
+					test_role(t, "admin", `{"Statement": {"Effect": "Allow", "Principal": {"AWS": "`+test_arn("user", "dev")+`"}, "Action": "sts:AssumeRole"}}`, allow_all),
+				)
+			},
+			paths: []string{"sts-assume-role => role/admin (admin)"},
+		},
+		{
+			name: "role trusting another account",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": "sts:AssumeRole", "Resource": "*"}`),
+					test_role(t, "admin", `{"Statement": {"Effect": "Allow", "Principal": {"AWS": "arn:aws:iam::999999999999:root"}, "Action": "sts:AssumeRole"}}`, allow_all),
+				)
+			},
+		},
+		{
+			name: "conditional trust",
+			model: func() *IAMModel {
+				return test_iam_model(
+					test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": "sts:AssumeRole", "Resource": "*"}`),
+					test_role(t, "admin", `{"Statement": {"Effect": "Allow", "Principal": {"AWS": "`+test_account+`"}, "Action": "sts:AssumeRole", "Condition": {"Bool": {"aws:MultiFactorAuthPresent": "true"}}}}`, allow_all),
+				)
+			},
+			paths: []string{"sts-assume-role => role/admin (admin) (conditional)"},
+		},
+		{
+			name: "unreadable permissions boundary",
+			model: func() *IAMModel {
+				user := test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": "sts:AssumeRole", "Resource": "*"}`)
+				user.BoundaryARN = "arn:aws:iam::" + test_account + ":policy/boundary"
+				return test_iam_model(user, test_role(t, "admin", account_trust, allow_all))
+			},
+		},
+		{
+			name: "chain of four pivots",
+			model: func() *IAMModel {
+				return test_iam_model(role_chain(t, 4)...)
+			},
+			paths: []string{
+				"sts-assume-role => role/hop1",
+				"sts-assume-role > sts-assume-role => role/hop2",
+				"sts-assume-role > sts-assume-role > sts-assume-role => role/hop3",
+				"sts-assume-role > sts-assume-role > sts-assume-role > sts-assume-role => role/hop4 (admin)",
+			},
+		},
+		{
+			name: "chain longer than the maximum depth",
+			model: func() *IAMModel {
+				return test_iam_model(role_chain(t, 5)...)
+			},
+			paths: []string{
+				"sts-assume-role => role/hop1",
+				"sts-assume-role > sts-assume-role => role/hop2",
+				"sts-assume-role > sts-assume-role > sts-assume-role => role/hop3",
+				"sts-assume-role > sts-assume-role > sts-assume-role > sts-assume-role => role/hop4",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		model := test.model()
+		report := AnalyzePrivesc(model, model.Users["dev"])
+		if report.Admin {
+			t.Errorf("%s: the principal is reported as administrator", test.name)
+		}
+		if got := describe_paths(report.Paths); strings.Join(got, "\n") != strings.Join(test.paths, "\n") {
+			t.Errorf("%s: paths\n%s\nwant\n%s", test.name, strings.Join(got, "\n"), strings.Join(test.paths, "\n"))
+		}
+		if len(report.Findings) != len(report.Paths) {
+			t.Errorf("%s: %d findings for %d paths", test.name, len(report.Findings), len(report.Paths))
+		}
+	}
+}
+
+// role_chain builds the user dev and hops roles, each trusting the previous principal, the last one is an administrator
+func role_chain(t *testing.T, hops int) []*IAMPrincipal {
+	principals := []*IAMPrincipal{test_principal(t, PRINCIPAL_USER, "dev")}
+	for hop := 1; hop <= hops; hop++ {
+		trust := `{"Statement": {"Effect": "Allow", "Principal": {"AWS": "` + principals[hop-1].ARN + `"}, "Action": "sts:AssumeRole"}}`
+		var statements []string
+		if hop == hops {
+			statements = append(statements, allow_all)
+		}
+		principals = append(principals, test_role(t, "hop"+string(rune('0'+hop)), trust, statements...))
+	}
+	return principals
+}
+
+func TestAnalyzePrivescAdmin(t *testing.T) {
+	use_run_directory(t)
+	tests := []struct {
+		name   string
+		policy string
+		admin  bool
+	}{
+		{"every action", allow_all, true},
+		{"every action but iam", `{"Effect": "Allow", "NotAction": ["iam:*", "organizations:*"], "Resource": "*"}`, false},
+		{"every action on some resources", `{"Effect": "Allow", "Action": "*", "Resource": "arn:aws:s3:::*"}`, false},
+		{"iam only", `{"Effect": "Allow", "Action": "iam:*", "Resource": "*"}`, false},
+	}
+	for _, test := range tests {
+		model := test_iam_model(test_principal(t, PRINCIPAL_USER, "dev", test.policy))
+		if report := AnalyzePrivesc(model, model.Users["dev"]); report.Admin != test.admin {
+			t.Errorf("%s: admin %v, want %v", test.name, report.Admin, test.admin)
+		}
+	}
+}
+
+func TestAnalyzePrivescLambdaCode(t *testing.T) {
+	use_run_directory(t)
+	function_arn := "arn:aws:lambda:eu-west-1:" + test_account + ":function:worker"
+	store_results(t, "eu-west-1", "lambda", map[string]string{
+		"ListFunctions": `{"Functions": [{"FunctionName": "worker", "FunctionArn": "` + function_arn + `", "Role": "` + test_arn("role", "worker") + `"}]}`,
+	})
+	for resource, paths := range map[string]string{
+		function_arn: "lambda-update-function-code => role/worker (admin)",
+		"arn:aws:lambda:eu-west-1:" + test_account + ":function:other": "",
+	} {
+		model := test_iam_model(
+			test_principal(t, PRINCIPAL_USER, "dev", `{"Effect": "Allow", "Action": "lambda:UpdateFunctionCode", "Resource": "`+resource+`"}`),
+			test_role(t, "worker", lambda_trust, allow_all),
+		)
+		report := AnalyzePrivesc(model, model.Users["dev"])
+		if got := strings.Join(describe_paths(report.Paths), "\n"); got != paths {
+			t.Errorf("%s: paths %q, want %q", resource, got, paths)
+		}
+	}
+}
+
+func TestPrivescFinding(t *testing.T) {
+	tests := []struct {
+		admin, conditional bool
+		id, severity       string
+	}{
+		{true, false, "privesc-admin", SEVERITY_CRITICAL},
+		{true, true, "privesc-admin", SEVERITY_HIGH},
+		{false, false, "privesc-pivot", SEVERITY_MEDIUM},
+		{false, true, "privesc-pivot", SEVERITY_LOW},
+	}
+	steps := []PrivescStep{
+		{Technique: "sts-assume-role", Actions: []string{"sts:AssumeRole"}},
+		{Technique: "passrole-lambda", Actions: []string{"iam:PassRole", "lambda:CreateFunction"}},
+		{Technique: "iam-update-assume-role-policy", Actions: []string{"iam:UpdateAssumeRolePolicy", "sts:AssumeRole"}},
+	}
+	for _, test := range tests {
+		path := PrivescPath{Target: test_arn("role", "admin"), Admin: test.admin, Conditional: test.conditional, Steps: steps}
+		finding := path.finding(test_arn("user", "dev"))
+		if finding.ID != test.id || finding.Severity != test.severity || finding.Resource != test_arn("user", "dev") {
+			t.Errorf("admin %v conditional %v: %s %s, want %s %s", test.admin, test.conditional, finding.ID, finding.Severity, test.id, test.severity)
+		}
+		if !strings.Contains(finding.Remediation, "sts:AssumeRole, iam:PassRole, lambda:CreateFunction, iam:UpdateAssumeRolePolicy in") {
+			t.Errorf("remediation %q", finding.Remediation)
+		}
+		if !strings.HasSuffix(finding.Description, "sts-assume-role, then passrole-lambda, then iam-update-assume-role-policy") {
+			t.Errorf("description %q", finding.Description)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package analyze
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// SECRETS_FILE is written to utils.FILEPATH by scan-secrets
+const SECRETS_FILE = "scan-secrets.json"
+
+// The ruleset shipped with the binary, -rules files are merged on top of it
+//
+//go:embed secrets.json
+var embedded_secret_rules []byte
+
+// SecretRuleset lists the secret rules and the fields holding base64 encoded content (EC2 user data)
+type SecretRuleset struct {
+	Base64Keys []string     `json:"base64_keys,omitempty"`
+	Rules      []SecretRule `json:"rules"`
+}
+
+// SecretRule matches the string values of the stored responses. Pattern is a regular expression on the value,
+// its first group (or the whole match) is the secret. KeyPattern restricts the rule to the fields whose name matches,
+// Entropy is the minimum Shannon entropy (bits per character) of the secret, Severity defaults to high.
+type SecretRule struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Severity    string   `json:"severity,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	KeyPattern  string   `json:"key_pattern,omitempty"`
+	IgnoreKeys  []string `json:"ignore_keys,omitempty"`
+	Entropy     float64  `json:"entropy,omitempty"`
+	Disabled    bool     `json:"disabled,omitempty"`
+
+	pattern     *regexp.Regexp
+	key_pattern *regexp.Regexp
+}
+
+// SecretsReport is the result of scan-secrets, saved to SECRETS_FILE. The findings hold redacted previews,
+// the secrets themselves are never stored.
+type SecretsReport struct {
+	Rules    []string
+	Findings []Finding
+}
+
+const secret_remediation = "Rotate the secret, then store it in Secrets Manager or an SSM SecureString parameter instead of the resource configuration"
+
+// Object fields naming the value of a sibling field: [{"Name": "DB_PASSWORD", "Value": "..."}]
+var name_fields = []string{"Name", "name", "Key", "key", "ParameterKey", "OutputKey"}
+var value_fields = []string{"Value", "value", "ParameterValue", "OutputValue"}
+
+// LoadSecretRules parses the embedded ruleset, merges the override file into it (if any) and compiles the rules.
+// Rules are matched by name, a matching rule is replaced, "disabled": true turns a rule off.
+func LoadSecretRules(override string) (*SecretRuleset, error) {
+	ruleset := &SecretRuleset{}
+	if err := json.Unmarshal(embedded_secret_rules, ruleset); err != nil {
+		return nil, fmt.Errorf("embedded rules: %v", err)
+	}
+
+	if override != "" {
+		data, err := ioutil.ReadFile(override)
+		if err != nil {
+			return nil, err
+		}
+		user_ruleset := &SecretRuleset{}
+		if err := json.Unmarshal(data, user_ruleset); err != nil {
+			return nil, fmt.Errorf("%s: %v", override, err)
+		}
+		ruleset.merge(user_ruleset)
+	}
+
+	var rules []SecretRule
+	for _, rule := range ruleset.Rules {
+		if rule.Disabled {
+			continue
+		}
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("rule %s: %v", rule.Name, err)
+		}
+		rules = append(rules, rule)
+	}
+	ruleset.Rules = rules
+	return ruleset, nil
+}
+
+func (ruleset *SecretRuleset) merge(override *SecretRuleset) {
+	for _, key := range override.Base64Keys {
+		if !utils.Find(ruleset.Base64Keys, key) {
+			ruleset.Base64Keys = append(ruleset.Base64Keys, key)
+		}
+	}
+	for _, override_rule := range override.Rules {
+		replaced := false
+		for i := range ruleset.Rules {
+			if ruleset.Rules[i].Name == override_rule.Name {
+				ruleset.Rules[i] = override_rule
+				replaced = true
+			}
+		}
+		if !replaced {
+			ruleset.Rules = append(ruleset.Rules, override_rule)
+		}
+	}
+}
+
+func (rule *SecretRule) compile() error {
+	if rule.Pattern == "" {
+		return fmt.Errorf("no pattern")
+	}
+	if rule.Severity == "" {
+		rule.Severity = SEVERITY_HIGH
+	}
+	if _, ok := severity_ranks[rule.Severity]; !ok {
+		return fmt.Errorf("unknown severity %q", rule.Severity)
+	}
+	pattern, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return err
+	}
+	rule.pattern = pattern
+	if rule.KeyPattern != "" {
+		if rule.key_pattern, err = regexp.Compile(rule.KeyPattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// secret_scanner walks the stored responses and applies the rules to every string value
+type secret_scanner struct {
+	ruleset  *SecretRuleset
+	findings []Finding
+	seen     map[string][]string // secrets already reported per location and path
+}
+
+// ScanSecrets applies the rules to the stored responses of the services (all when empty) of every region
+func ScanSecrets(ruleset *SecretRuleset, services []string) *SecretsReport {
+	scanner := &secret_scanner{ruleset: ruleset, seen: make(map[string][]string)}
+	for _, region := range servicemaster.StoredRegions() {
+		for _, svc := range servicemaster.StoredServices(region, false) {
+			if len(services) > 0 && !utils.Find(services, "all") && !utils.Find(services, svc) {
+				continue
+			}
+			results, err := servicemaster.LoadResults(region, svc, false)
+			if err != nil {
+				continue
+			}
+			for apicall, body := range results {
+				var value interface{}
+				if json.Unmarshal(body, &value) != nil {
+					continue
+				}
+				location := Finding{Service: svc, Region: region, ApiCall: apicall}
+				scanner.walk(location, "$", "", value)
+			}
+		}
+	}
+
+	SortFindings(scanner.findings)
+	report := &SecretsReport{Findings: scanner.findings}
+	for _, rule := range ruleset.Rules {
+		report.Rules = append(report.Rules, rule.Name)
+	}
+	return report
+}
+
+// walk visits every string of a JSON value, key is the name of the field holding the value
+func (scanner *secret_scanner) walk(location Finding, path, key string, value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		named_key := ""
+		for _, field := range name_fields {
+			if name, ok := value[field].(string); ok {
+				named_key = name
+				break
+			}
+		}
+		for _, field := range sorted_fields(value) {
+			field_key := field
+			if utils.Find(value_fields, field) {
+				// {"Name": "DB_PASSWORD", "Value": ...} is named by its sibling, {"UserData": {"Value": ...}} by its parent
+				field_key = named_key
+				if field_key == "" {
+					field_key = key
+				}
+			}
+			scanner.walk(location, path+"."+field, field_key, value[field])
+		}
+	case []interface{}:
+		for i, item := range value {
+			scanner.walk(location, fmt.Sprintf("%s[%d]", path, i), key, item)
+		}
+	case string:
+		scanner.scan(location, path, key, value)
+		if utils.Find(scanner.ruleset.Base64Keys, key) {
+			if decoded, err := base64.StdEncoding.DecodeString(value); err == nil && utf8.Valid(decoded) {
+				scanner.scan(location, path+"(base64)", key, string(decoded))
+			}
+		}
+	}
+}
+
+func (scanner *secret_scanner) scan(location Finding, path, key, value string) {
+	for _, rule := range scanner.ruleset.Rules {
+		if utils.Find(rule.IgnoreKeys, key) {
+			continue
+		}
+		if rule.key_pattern != nil && !rule.key_pattern.MatchString(key) {
+			continue
+		}
+		for _, match := range rule.pattern.FindAllStringSubmatch(value, -1) {
+			secret := match[0]
+			if len(match) > 1 && match[1] != "" {
+				secret = match[1]
+			}
+			if rule.Entropy > 0 && shannon_entropy(secret) < rule.Entropy {
+				continue
+			}
+
+			// one hit per secret, the first rule wins: parts of an already reported secret are skipped
+			id := location.Service + "|" + location.Region + "|" + location.ApiCall + "|" + path
+			if reported(scanner.seen[id], secret) {
+				continue
+			}
+			scanner.seen[id] = append(scanner.seen[id], secret)
+
+			hit := location
+			hit.ID, hit.Title, hit.Severity, hit.Evidence, hit.Remediation = "secret-"+rule.Name, rule.Description, rule.Severity, path, secret_remediation
+			hit.Description = fmt.Sprintf("%s in the stored %s response: %s", rule.Description, location.ApiCall, redact(secret))
+			scanner.findings = append(scanner.findings, hit)
+		}
+	}
+}
+
+func reported(secrets []string, secret string) bool {
+	for _, known := range secrets {
+		if strings.Contains(known, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact keeps the first characters of a secret, enough to recognize it without disclosing it
+func redact(secret string) string {
+	runes := []rune(secret)
+	visible := 4
+	if len(runes) <= 8 {
+		visible = 1
+	}
+	hidden := len(runes) - visible
+	if hidden > 16 {
+		hidden = 16
+	}
+	return fmt.Sprintf("%s%s (%d chars)", string(runes[:visible]), strings.Repeat("*", hidden), len(runes))
+}
+
+// shannon_entropy returns the entropy of the string in bits per character
+func shannon_entropy(value string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func sorted_fields(object map[string]interface{}) []string {
+	fields := make([]string, 0, len(object))
+	for field := range object {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,329 @@
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/threatroute66/aws-enumerator/policy"
+)
+
+// SELF_FILE is written to utils.FILEPATH by analyze-self
+const SELF_FILE = "analyze-self.json"
+
+// Principal types of a caller identity
+const (
+	PRINCIPAL_USER             = "user"
+	PRINCIPAL_ROLE             = "role"
+	PRINCIPAL_ROOT             = "root"
+	PRINCIPAL_FEDERATED_USER   = "federated_user"
+	PRINCIPAL_UNSUPPORTED_TYPE = "unsupported"
+)
+
+// Principal is the IAM principal behind a caller identity ARN
+type Principal struct {
+	ARN     string
+	Account string
+	Type    string // one of the PRINCIPAL_* types
+	Name    string // user or role name
+}
+
+// ParsePrincipal resolves the principal of a sts:GetCallerIdentity ARN:
+// arn:aws:iam::123456789012:user/path/bob, arn:aws:sts::123456789012:assumed-role/audit/session, arn:aws:iam::123456789012:root
+func ParsePrincipal(arn string) (Principal, error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		return Principal{}, fmt.Errorf("invalid ARN %s", arn)
+	}
+	principal := Principal{ARN: arn, Account: parts[4], Type: PRINCIPAL_UNSUPPORTED_TYPE}
+
+	resource := strings.Split(parts[5], "/")
+	switch {
+	case resource[0] == "root":
+		principal.Type = PRINCIPAL_ROOT
+	case resource[0] == "user" && len(resource) > 1:
+		principal.Type = PRINCIPAL_USER
+		principal.Name = resource[len(resource)-1]
+	case resource[0] == "assumed-role" && len(resource) > 1:
+		principal.Type = PRINCIPAL_ROLE
+		principal.Name = resource[1]
+	case resource[0] == "role" && len(resource) > 1:
+		principal.Type = PRINCIPAL_ROLE
+		principal.Name = resource[len(resource)-1]
+	case resource[0] == "federated-user" && len(resource) > 1:
+		principal.Type = PRINCIPAL_FEDERATED_USER
+		principal.Name = resource[1]
+	}
+	return principal, nil
+}
+
+// SelfPolicies are the policies of the caller that could be read
+type SelfPolicies struct {
+	Principal  Principal
+	Policies   []policy.Policy
+	Boundary   []policy.Policy
+	Unreadable []string // api calls that failed, the policies they return are missing from the evaluation
+}
+
+// SelfReport is the effective permission analysis of the caller, saved to SELF_FILE
+type SelfReport struct {
+	Principal  Principal
+	Policies   []string
+	Boundary   []string `json:",omitempty"`
+	Unreadable []string `json:",omitempty"`
+	Grants     []string // action patterns of the unconditional Allow statements
+	Actions    []policy.Result
+}
+
+// policy_gatherer collects the policies of a principal with the iam api, managed policy documents are fetched once
+type policy_gatherer struct {
+	ctx     context.Context
+	client  *iam.Client
+	result  *SelfPolicies
+	managed map[string]*policy.Document
+}
+
+// GatherSelfPolicies reads the identity policies, group policies and permissions boundary of the caller.
+// Failed api calls are recorded in Unreadable, the analysis goes on with whatever could be read.
+func GatherSelfPolicies(ctx context.Context, cfg aws.Config, principal Principal) *SelfPolicies {
+	gatherer := &policy_gatherer{
+		ctx:     ctx,
+		client:  iam.NewFromConfig(cfg),
+		result:  &SelfPolicies{Principal: principal},
+		managed: make(map[string]*policy.Document),
+	}
+
+	switch principal.Type {
+	case PRINCIPAL_USER:
+		gatherer.user_policies(principal.Name)
+	case PRINCIPAL_ROLE:
+		gatherer.role_policies(principal.Name)
+	}
+	return gatherer.result
+}
+
+func (gatherer *policy_gatherer) failed(apicall string, err error) {
+	gatherer.result.Unreadable = append(gatherer.result.Unreadable, fmt.Sprintf("iam:%s: %v", apicall, err))
+}
+
+func (gatherer *policy_gatherer) user_policies(user_name string) {
+	user, err := gatherer.client.GetUser(gatherer.ctx, &iam.GetUserInput{UserName: aws.String(user_name)})
+	if err != nil {
+		gatherer.failed("GetUser", err)
+	} else if user.User.PermissionsBoundary != nil {
+		gatherer.boundary(aws.ToString(user.User.PermissionsBoundary.PermissionsBoundaryArn))
+	}
+
+	attached := iam.NewListAttachedUserPoliciesPaginator(gatherer.client, &iam.ListAttachedUserPoliciesInput{UserName: aws.String(user_name)})
+	for attached.HasMorePages() {
+		page, err := attached.NextPage(gatherer.ctx)
+		if err != nil {
+			gatherer.failed("ListAttachedUserPolicies", err)
+			break
+		}
+		for _, attached_policy := range page.AttachedPolicies {
+			gatherer.managed_policy(aws.ToString(attached_policy.PolicyArn), "user attached")
+		}
+	}
+
+	inline := iam.NewListUserPoliciesPaginator(gatherer.client, &iam.ListUserPoliciesInput{UserName: aws.String(user_name)})
+	for inline.HasMorePages() {
+		page, err := inline.NextPage(gatherer.ctx)
+		if err != nil {
+			gatherer.failed("ListUserPolicies", err)
+			break
+		}
+		for _, policy_name := range page.PolicyNames {
+			output, err := gatherer.client.GetUserPolicy(gatherer.ctx, &iam.GetUserPolicyInput{UserName: aws.String(user_name), PolicyName: aws.String(policy_name)})
+			if err != nil {
+				gatherer.failed("GetUserPolicy", err)
+				continue
+			}
+			gatherer.inline_policy(policy_name, "user inline", aws.ToString(output.PolicyDocument))
+		}
+	}
+
+	groups := iam.NewListGroupsForUserPaginator(gatherer.client, &iam.ListGroupsForUserInput{UserName: aws.String(user_name)})
+	for groups.HasMorePages() {
+		page, err := groups.NextPage(gatherer.ctx)
+		if err != nil {
+			gatherer.failed("ListGroupsForUser", err)
+			break
+		}
+		for _, group := range page.Groups {
+			gatherer.group_policies(aws.ToString(group.GroupName))
+		}
+	}
+}
+
+func (gatherer *policy_gatherer) group_policies(group_name string) {
+	attached := iam.NewListAttachedGroupPoliciesPaginator(gatherer.client, &iam.ListAttachedGroupPoliciesInput{GroupName: aws.String(group_name)})
+	for attached.HasMorePages() {
+		page, err := attached.NextPage(gatherer.ctx)
+		if err != nil {
+			gatherer.failed("ListAttachedGroupPolicies", err)
+			break
+		}
+		for _, attached_policy := range page.AttachedPolicies {
+			gatherer.managed_policy(aws.ToString(attached_policy.PolicyArn), "group "+group_name+" attached")
+		}
+	}
+
+	inline := iam.NewListGroupPoliciesPaginator(gatherer.client, &iam.ListGroupPoliciesInput{GroupName: aws.String(group_name)})
+	for inline.HasMorePages() {
+		page, err := inline.NextPage(gatherer.ctx)
+		if err != nil {
+			gatherer.failed("ListGroupPolicies", err)
+			break
+		}
+		for _, policy_name := range page.PolicyNames {
+			output, err := gatherer.client.GetGroupPolicy(gatherer.ctx, &iam.GetGroupPolicyInput{GroupName: aws.String(group_name), PolicyName: aws.String(policy_name)})
+			if err != nil {
+				gatherer.failed("GetGroupPolicy", err)
+				continue
+			}
+			gatherer.inline_policy(policy_name, "group "+group_name+" inline", aws.ToString(output.PolicyDocument))
+		}
+	}
+}
+
+func (gatherer *policy_gatherer) role_policies(role_name string) {
+	role, err := gatherer.client.GetRole(gatherer.ctx, &iam.GetRoleInput{RoleName: aws.String(role_name)})
+	if err != nil {
+		gatherer.failed("GetRole", err)
+	} else if role.Role.PermissionsBoundary != nil {
+		gatherer.boundary(aws.ToString(role.Role.PermissionsBoundary.PermissionsBoundaryArn))
+	}
+
+	attached := iam.NewListAttachedRolePoliciesPaginator(gatherer.client, &iam.ListAttachedRolePoliciesInput{RoleName: aws.String(role_name)})
+	for attached.HasMorePages() {
+		page, err := attached.NextPage(gatherer.ctx)
+		if err != nil {
+			gatherer.failed("ListAttachedRolePolicies", err)
+			break
+		}
+		for _, attached_policy := range page.AttachedPolicies {
+			gatherer.managed_policy(aws.ToString(attached_policy.PolicyArn), "role attached")
+		}
+	}
+
+	inline := iam.NewListRolePoliciesPaginator(gatherer.client, &iam.ListRolePoliciesInput{RoleName: aws.String(role_name)})
+	for inline.HasMorePages() {
+		page, err := inline.NextPage(gatherer.ctx)
+		if err != nil {
+			gatherer.failed("ListRolePolicies", err)
+			break
+		}
+		for _, policy_name := range page.PolicyNames {
+			output, err := gatherer.client.GetRolePolicy(gatherer.ctx, &iam.GetRolePolicyInput{RoleName: aws.String(role_name), PolicyName: aws.String(policy_name)})
+			if err != nil {
+				gatherer.failed("GetRolePolicy", err)
+				continue
+			}
+			gatherer.inline_policy(policy_name, "role inline", aws.ToString(output.PolicyDocument))
+		}
+	}
+}
+
+func (gatherer *policy_gatherer) inline_policy(name, source, document string) {
+	parsed, err := policy.Parse(document)
+	if err != nil {
+		gatherer.result.Unreadable = append(gatherer.result.Unreadable, fmt.Sprintf("%s %s: %v", source, name, err))
+		return
+	}
+	gatherer.result.Policies = append(gatherer.result.Policies, policy.Policy{Name: name, Source: source, Document: parsed})
+}
+
+func (gatherer *policy_gatherer) managed_policy(arn, source string) {
+	if document := gatherer.managed_document(arn); document != nil {
+		gatherer.result.Policies = append(gatherer.result.Policies, policy.Policy{Name: arn, Source: source, Document: document})
+	}
+}
+
+func (gatherer *policy_gatherer) boundary(arn string) {
+	if document := gatherer.managed_document(arn); document != nil {
+		gatherer.result.Boundary = append(gatherer.result.Boundary, policy.Policy{Name: arn, Source: "permissions boundary", Document: document})
+	} else {
+		gatherer.result.Boundary = append(gatherer.result.Boundary, unreadable_boundary(arn))
+		gatherer.result.Unreadable = append(gatherer.result.Unreadable, "permissions boundary "+arn+": unreadable, every action is evaluated as denied")
+	}
+}
+
+// managed_document returns the default version of a managed policy, nil if it can't be read
+func (gatherer *policy_gatherer) managed_document(arn string) *policy.Document {
+	if document, ok := gatherer.managed[arn]; ok {
+		return document
+	}
+	gatherer.managed[arn] = nil
+
+	managed, err := gatherer.client.GetPolicy(gatherer.ctx, &iam.GetPolicyInput{PolicyArn: aws.String(arn)})
+	if err != nil {
+		gatherer.failed("GetPolicy", err)
+		return nil
+	}
+	version, err := gatherer.client.GetPolicyVersion(gatherer.ctx, &iam.GetPolicyVersionInput{PolicyArn: aws.String(arn), VersionId: managed.Policy.DefaultVersionId})
+	if err != nil {
+		gatherer.failed("GetPolicyVersion", err)
+		return nil
+	}
+	document, err := policy.Parse(aws.ToString(version.PolicyVersion.Document))
+	if err != nil {
+		gatherer.result.Unreadable = append(gatherer.result.Unreadable, fmt.Sprintf("%s: %v", arn, err))
+		return nil
+	}
+	gatherer.managed[arn] = document
+	return document
+}
+
+// EvaluateSelf evaluates the known actions and every action named in the policies against the gathered policies
+func EvaluateSelf(self *SelfPolicies, known_actions []string) *SelfReport {
+	report := &SelfReport{Principal: self.Principal, Unreadable: self.Unreadable}
+	for _, p := range self.Policies {
+		report.Policies = append(report.Policies, p.Source+" "+p.Name)
+	}
+	for _, p := range self.Boundary {
+		report.Boundary = append(report.Boundary, p.Name)
+	}
+
+	actions := make(map[string]bool)
+	for _, action := range known_actions {
+		actions[action] = true
+	}
+	grants := make(map[string]bool)
+	for _, p := range self.Policies {
+		for _, statement := range p.Document.Statement {
+			for _, action := range statement.Action {
+				if !strings.ContainsAny(action, "*?") {
+					actions[action] = true
+				} else if strings.EqualFold(statement.Effect, "Allow") && !statement.HasCondition() {
+					grants[action] = true
+				}
+			}
+			if len(statement.NotAction) > 0 && strings.EqualFold(statement.Effect, "Allow") && !statement.HasCondition() {
+				grants["NOT "+strings.Join(statement.NotAction, ",")] = true
+			}
+		}
+	}
+	report.Grants = sorted_keys(grants)
+
+	for _, action := range sorted_keys(actions) {
+		if self.Principal.Type == PRINCIPAL_ROOT {
+			report.Actions = append(report.Actions, policy.Result{Action: action, Decision: policy.DECISION_ALLOWED, Reason: "root user"})
+			continue
+		}
+		report.Actions = append(report.Actions, policy.Evaluate(action, self.Policies, self.Boundary))
+	}
+	return report
+}
+
+func sorted_keys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,87 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/threatroute66/aws-enumerator/policy"
+)
+
+func TestParsePrincipal(t *testing.T) {
+	tests := []struct {
+		arn       string
+		principal Principal
+	}{
+		{"arn:aws:iam::123456789012:user/dev/bob", Principal{Account: "123456789012", Type: PRINCIPAL_USER, Name: "bob"}},
+		{"arn:aws:sts::123456789012:assumed-role/audit/session", Principal{Account: "123456789012", Type: PRINCIPAL_ROLE, Name: "audit"}},
+		{"arn:aws:iam::123456789012:role/service/app", Principal{Account: "123456789012", Type: PRINCIPAL_ROLE, Name: "app"}},
+		{"arn:aws:iam::123456789012:root", Principal{Account: "123456789012", Type: PRINCIPAL_ROOT}},
+		{"arn:aws:sts::123456789012:federated-user/alice", Principal{Account: "123456789012", Type: PRINCIPAL_FEDERATED_USER, Name: "alice"}},
+		{"arn:aws:iam::123456789012:group/admins", Principal{Account: "123456789012", Type: PRINCIPAL_UNSUPPORTED_TYPE}},
+	}
+	for _, test := range tests {
+		principal, err := ParsePrincipal(test.arn)
+		test.principal.ARN = test.arn
+		if err != nil || principal != test.principal {
+			t.Errorf("%s: got %+v %v, want %+v", test.arn, principal, err, test.principal)
+		}
+	}
+	if _, err := ParsePrincipal("not-an-arn"); err == nil {
+		t.Error("invalid ARN parsed")
+	}
+}
+
+func TestEvaluateSelf(t *testing.T) {
+	document := func(json string) *policy.Document {
+		doc, err := policy.Parse(json)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	self := &SelfPolicies{
+		Principal: Principal{Type: PRINCIPAL_USER, Name: "bob"},
+		Policies: []policy.Policy{
+			{Name: "read", Source: "user attached", Document: document(`{"Statement": [{"Effect": "Allow", "Action": ["s3:Get*", "iam:PassRole"], "Resource": "*"}, {"Effect": "Allow", "Action": "ec2:RunInstances", "Resource": "*", "Condition": {"Bool": {"aws:MultiFactorAuthPresent": "true"}}}]}`)},
+			{Name: "deny", Source: "user inline", Document: document(`{"Statement": {"Effect": "Deny", "Action": "s3:GetObject", "Resource": "*"}}`)},
+		},
+		Boundary: []policy.Policy{
+			{Name: "boundary", Document: document(`{"Statement": {"Effect": "Allow", "Action": ["s3:*", "ec2:*"], "Resource": "*"}}`)},
+		},
+	}
+
+	report := EvaluateSelf(self, []string{"s3:GetBucketPolicy", "s3:GetObject", "lambda:ListFunctions"})
+	if !reflect.DeepEqual(report.Grants, []string{"s3:Get*"}) {
+		t.Errorf("grants %v", report.Grants)
+	}
+	want := map[string]string{
+		"s3:GetBucketPolicy":   policy.DECISION_ALLOWED,
+		"s3:GetObject":         policy.DECISION_DENIED,
+		"lambda:ListFunctions": policy.DECISION_IMPLICIT_DENY,
+		"iam:PassRole":         policy.DECISION_IMPLICIT_DENY, // named in the policies, outside the boundary
+		"ec2:RunInstances":     policy.DECISION_UNKNOWN,
+	}
+	if len(report.Actions) != len(want) {
+		t.Errorf("actions %+v", report.Actions)
+	}
+	for _, result := range report.Actions {
+		if result.Decision != want[result.Action] {
+			t.Errorf("%s: decision %s, want %s", result.Action, result.Decision, want[result.Action])
+		}
+	}
+
+	// an unreadable boundary denies everything, like in the IAM model of the stored results
+	self.Boundary = []policy.Policy{unreadable_boundary("arn:aws:iam::123456789012:policy/boundary")}
+	for _, result := range EvaluateSelf(self, []string{"s3:GetBucketPolicy"}).Actions {
+		if result.Decision == policy.DECISION_ALLOWED || result.Decision == policy.DECISION_UNKNOWN {
+			t.Errorf("unreadable boundary: %s %s", result.Action, result.Decision)
+		}
+	}
+
+	self.Principal.Type = PRINCIPAL_ROOT
+	for _, result := range EvaluateSelf(self, []string{"s3:GetObject"}).Actions {
+		if result.Decision != policy.DECISION_ALLOWED {
+			t.Errorf("root user: %s %s", result.Action, result.Decision)
+		}
+	}
+}
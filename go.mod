@@ -1,21 +1,27 @@
 module github.com/threatroute66/aws-enumerator
 
-go 1.22
+go 1.24
 
 toolchain go1.24.2
 
 require (
 	github.com/aws/aws-sdk-go v1.44.0
+	github.com/aws/aws-sdk-go-v2 v1.47.1
 	github.com/aws/aws-sdk-go-v2/config v1.29.16
+	github.com/aws/aws-sdk-go-v2/credentials v1.17.69
+	github.com/aws/aws-sdk-go-v2/service/accessanalyzer v1.49.1
 	github.com/aws/aws-sdk-go-v2/service/acm v1.32.2
 	github.com/aws/aws-sdk-go-v2/service/amplify v1.33.2
 	github.com/aws/aws-sdk-go-v2/service/apigateway v1.31.2
+	github.com/aws/aws-sdk-go-v2/service/apigatewayv2 v1.35.2
+	github.com/aws/aws-sdk-go-v2/service/appconfig v1.44.2
 	github.com/aws/aws-sdk-go-v2/service/appmesh v1.30.3
 	github.com/aws/aws-sdk-go-v2/service/appsync v1.47.1
 	github.com/aws/aws-sdk-go-v2/service/athena v1.51.1
 	github.com/aws/aws-sdk-go-v2/service/autoscaling v1.53.2
 	github.com/aws/aws-sdk-go-v2/service/backup v1.42.2
 	github.com/aws/aws-sdk-go-v2/service/batch v1.52.5
+	github.com/aws/aws-sdk-go-v2/service/bedrock v1.63.0
 	github.com/aws/aws-sdk-go-v2/service/chime v1.36.3
 	github.com/aws/aws-sdk-go-v2/service/cloud9 v1.29.3
 	github.com/aws/aws-sdk-go-v2/service/clouddirectory v1.25.3
@@ -25,12 +31,18 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/cloudhsmv2 v1.30.3
 	github.com/aws/aws-sdk-go-v2/service/cloudsearch v1.27.3
 	github.com/aws/aws-sdk-go-v2/service/cloudtrail v1.49.2
+	github.com/aws/aws-sdk-go-v2/service/cloudwatch v1.57.2
+	github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs v1.82.3
+	github.com/aws/aws-sdk-go-v2/service/codeartifact v1.39.2
 	github.com/aws/aws-sdk-go-v2/service/codebuild v1.61.1
 	github.com/aws/aws-sdk-go-v2/service/codecommit v1.28.3
 	github.com/aws/aws-sdk-go-v2/service/codedeploy v1.30.5
 	github.com/aws/aws-sdk-go-v2/service/codepipeline v1.42.1
 	github.com/aws/aws-sdk-go-v2/service/codestar v1.23.4
+	github.com/aws/aws-sdk-go-v2/service/cognitoidentity v1.34.0
+	github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider v1.61.0
 	github.com/aws/aws-sdk-go-v2/service/comprehend v1.36.5
+	github.com/aws/aws-sdk-go-v2/service/configservice v1.63.0
 	github.com/aws/aws-sdk-go-v2/service/datapipeline v1.26.3
 	github.com/aws/aws-sdk-go-v2/service/datasync v1.49.2
 	github.com/aws/aws-sdk-go-v2/service/dax v1.24.3
@@ -40,11 +52,16 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.43.3
 	github.com/aws/aws-sdk-go-v2/service/ec2 v1.225.1
 	github.com/aws/aws-sdk-go-v2/service/ecr v1.44.2
+	github.com/aws/aws-sdk-go-v2/service/ecrpublic v1.47.1
 	github.com/aws/aws-sdk-go-v2/service/ecs v1.57.4
+	github.com/aws/aws-sdk-go-v2/service/efs v1.41.18
 	github.com/aws/aws-sdk-go-v2/service/eks v1.66.0
 	github.com/aws/aws-sdk-go-v2/service/elasticache v1.46.2
 	github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk v1.29.3
+	github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing v1.41.1
+	github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2 v1.63.1
 	github.com/aws/aws-sdk-go-v2/service/elastictranscoder v1.28.3
+	github.com/aws/aws-sdk-go-v2/service/eventbridge v1.55.0
 	github.com/aws/aws-sdk-go-v2/service/firehose v1.37.6
 	github.com/aws/aws-sdk-go-v2/service/fms v1.40.4
 	github.com/aws/aws-sdk-go-v2/service/fsx v1.54.1
@@ -55,7 +72,9 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/guardduty v1.54.6
 	github.com/aws/aws-sdk-go-v2/service/health v1.30.3
 	github.com/aws/aws-sdk-go-v2/service/iam v1.42.1
+	github.com/aws/aws-sdk-go-v2/service/identitystore v1.47.0
 	github.com/aws/aws-sdk-go-v2/service/inspector v1.26.3
+	github.com/aws/aws-sdk-go-v2/service/inspector2 v1.48.2
 	github.com/aws/aws-sdk-go-v2/service/iot v1.64.3
 	github.com/aws/aws-sdk-go-v2/service/iotanalytics v1.27.3
 	github.com/aws/aws-sdk-go-v2/service/kafka v1.39.4
@@ -75,6 +94,7 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/mediatailor v1.48.1
 	github.com/aws/aws-sdk-go-v2/service/mobile v1.21.3
 	github.com/aws/aws-sdk-go-v2/service/mq v1.29.1
+	github.com/aws/aws-sdk-go-v2/service/opensearch v1.70.2
 	github.com/aws/aws-sdk-go-v2/service/opsworks v1.27.4
 	github.com/aws/aws-sdk-go-v2/service/organizations v1.38.4
 	github.com/aws/aws-sdk-go-v2/service/pinpoint v1.35.3
@@ -93,6 +113,8 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/secretsmanager v1.35.6
 	github.com/aws/aws-sdk-go-v2/service/securityhub v1.57.5
 	github.com/aws/aws-sdk-go-v2/service/servicecatalog v1.34.1
+	github.com/aws/aws-sdk-go-v2/service/sesv2 v1.77.0
+	github.com/aws/aws-sdk-go-v2/service/sfn v1.41.2
 	github.com/aws/aws-sdk-go-v2/service/shield v1.30.3
 	github.com/aws/aws-sdk-go-v2/service/signer v1.27.3
 	github.com/aws/aws-sdk-go-v2/service/sms v1.25.3
@@ -100,6 +122,7 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/sns v1.34.6
 	github.com/aws/aws-sdk-go-v2/service/sqs v1.38.7
 	github.com/aws/aws-sdk-go-v2/service/ssm v1.59.2
+	github.com/aws/aws-sdk-go-v2/service/ssoadmin v1.49.1
 	github.com/aws/aws-sdk-go-v2/service/storagegateway v1.37.2
 	github.com/aws/aws-sdk-go-v2/service/sts v1.33.21
 	github.com/aws/aws-sdk-go-v2/service/support v1.27.3
@@ -107,22 +130,23 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/transfer v1.60.2
 	github.com/aws/aws-sdk-go-v2/service/translate v1.29.3
 	github.com/aws/aws-sdk-go-v2/service/waf v1.26.3
+	github.com/aws/aws-sdk-go-v2/service/wafv2 v1.72.0
 	github.com/aws/aws-sdk-go-v2/service/workdocs v1.26.3
 	github.com/aws/aws-sdk-go-v2/service/worklink v1.23.2
 	github.com/aws/aws-sdk-go-v2/service/workmail v1.31.3
 	github.com/aws/aws-sdk-go-v2/service/workspaces v1.57.1
 	github.com/aws/aws-sdk-go-v2/service/xray v1.31.6
+	github.com/aws/smithy-go v1.28.1
+	gopkg.in/yaml.v2 v2.2.8
 )
 
 require (
-	github.com/aws/aws-sdk-go-v2 v1.36.4 // indirect
-	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.6.10 // indirect
-	github.com/aws/aws-sdk-go-v2/credentials v1.17.69 // indirect
+	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.7.18 // indirect
 	github.com/aws/aws-sdk-go-v2/feature/ec2/imds v1.16.31 // indirect
-	github.com/aws/aws-sdk-go-v2/internal/configsources v1.3.35 // indirect
-	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.6.35 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/configsources v1.5.4 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.8.4 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/ini v1.8.3 // indirect
-	github.com/aws/aws-sdk-go-v2/internal/v4a v1.3.35 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/v4a v1.5.4 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/accept-encoding v1.12.3 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/checksum v1.7.3 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/endpoint-discovery v1.10.16 // indirect
@@ -130,6 +154,5 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/internal/s3shared v1.18.16 // indirect
 	github.com/aws/aws-sdk-go-v2/service/sso v1.25.4 // indirect
 	github.com/aws/aws-sdk-go-v2/service/ssooidc v1.30.2 // indirect
-	github.com/aws/smithy-go v1.22.2 // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
 )
@@ -0,0 +1,220 @@
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/threatroute66/aws-enumerator/analyze"
+	"github.com/threatroute66/aws-enumerator/policy"
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// HandleAnalyzeSelfCommand reads the policies of the caller and evaluates them offline, the report lists the actions granted to the caller.
+// ctx cancels the iam api calls, an interrupted run saves no report.
+func HandleAnalyzeSelfCommand(ctx context.Context, profile, assumeRole, externalID, roleSessionName, catalogFile *string, all *bool) {
+	registry := mustLoadRegistry(*catalogFile)
+
+	cfg, identity, _ := loadAWSConfig(ctx, *profile, *assumeRole, *externalID, *roleSessionName)
+	principal, err := analyze.ParsePrincipal(aws.ToString(identity.Arn))
+	if err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("Unable to resolve the caller identity"))
+		fmt.Println(utils.Red("Trace:"), utils.Yellow(err))
+		os.Exit(1)
+	}
+	switch principal.Type {
+	case analyze.PRINCIPAL_FEDERATED_USER, analyze.PRINCIPAL_UNSUPPORTED_TYPE:
+		fmt.Println(utils.Red("Error:"), utils.Yellow("The policies of"), utils.Red(principal.ARN), utils.Yellow("can't be read with the iam api"))
+		os.Exit(1)
+	case analyze.PRINCIPAL_ROOT:
+		fmt.Println(utils.Yellow("Warning:"), utils.Yellow("Root user credentials, every action is allowed unless a service control policy denies it"))
+	}
+
+	self := analyze.GatherSelfPolicies(ctx, cfg, principal)
+	// policies missing because of the interruption would be evaluated as denied
+	if ctx.Err() != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("Analysis stopped ("+ctx.Err().Error()+") before the policies were read, no report was saved"))
+		os.Exit(1)
+	}
+	for _, unreadable := range self.Unreadable {
+		fmt.Println(utils.Yellow("Warning:"), utils.Yellow(unreadable))
+	}
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Policies of"), utils.Green(principal.Type+" "+principal.Name)+utils.Yellow(":"), utils.Green(len(self.Policies)), utils.Yellow("identity,"), utils.Green(len(self.Boundary)), utils.Yellow("boundary"))
+
+	report := analyze.EvaluateSelf(self, registry.IAMActions())
+	saveAnalysis(analyze.SELF_FILE, report)
+	printSelfReport(report, *all)
+}
+
+// printSelfReport prints the allowed and conditional actions, and the denied ones too with -all
+func printSelfReport(report *analyze.SelfReport, all bool) {
+	width := len("ACTION")
+	for _, result := range report.Actions {
+		if len(result.Action) > width {
+			width = len(result.Action)
+		}
+	}
+
+	if len(report.Grants) > 0 {
+		fmt.Println(utils.Green("Message: "), utils.Yellow("Granted patterns:"), utils.Green(strings.Join(report.Grants, " ")))
+	}
+
+	counts := make(map[string]int)
+	fmt.Printf("\n%-*s  %-13s  %s\n", width, "ACTION", "DECISION", "RESOURCES / REASON")
+	for _, result := range report.Actions {
+		counts[result.Decision]++
+		decision := fmt.Sprintf("%-13s", result.Decision)
+		switch result.Decision {
+		case policy.DECISION_ALLOWED:
+			fmt.Printf("%-*s  %s  %s\n", width, result.Action, utils.Green(decision), strings.Join(result.Resources, ","))
+		case policy.DECISION_UNKNOWN:
+			fmt.Printf("%-*s  %s  %s\n", width, result.Action, utils.Yellow(decision), result.Reason)
+		default:
+			if all {
+				fmt.Printf("%-*s  %s  %s\n", width, result.Action, utils.Red(decision), result.Reason)
+			}
+		}
+	}
+	fmt.Println()
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Actions:"), utils.Green(counts[policy.DECISION_ALLOWED]), utils.Yellow("allowed,"), counts[policy.DECISION_UNKNOWN], utils.Yellow("conditional,"), utils.Red(counts[policy.DECISION_DENIED]), utils.Yellow("denied,"), utils.Red(counts[policy.DECISION_IMPLICIT_DENY]), utils.Yellow("not granted, see"), utils.Yellow(filepath.Join(utils.FILEPATH, analyze.SELF_FILE)))
+}
+
+// saveAnalysis writes an analysis report to utils.FILEPATH
+func saveAnalysis(filename string, report interface{}) {
+	if err := os.MkdirAll(utils.FILEPATH, 0755); err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow(err))
+		os.Exit(1)
+	}
+	if err := ioutil.WriteFile(filepath.Join(utils.FILEPATH, filename), []byte(utils.PackResponse(report)), 0644); err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow(err))
+		os.Exit(1)
+	}
+}
+
+// HandleAnalyzeCommand runs the offline analyses of the stored enumeration results
+func HandleAnalyzeCommand(command string, principal *string) {
+	switch command {
+	case "privesc":
+		analyzePrivesc(*principal)
+	case "exposure":
+		analyzeExposure()
+	default:
+		fmt.Fprint(os.Stderr, Cloudrider_analyze_help)
+		os.Exit(1)
+	}
+}
+
+// analyzePrivesc searches the stored IAM data for escalation paths of the principal (default: the enumerating identity)
+func analyzePrivesc(principalARN string) {
+	model, err := analyze.LoadIAMModel()
+	if err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("No IAM results found in"), utils.Red(utils.FILEPATH))
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Run `./aws-enumerator enum -services iam,sts` first"))
+		fmt.Println(utils.Red("Trace:"), utils.Yellow(err))
+		os.Exit(1)
+	}
+
+	if principalARN == "" {
+		principalARN = storedCallerARN()
+	}
+	caller, err := analyze.ParsePrincipal(principalARN)
+	if err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("Unknown principal to analyze"))
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Use -principal ARN, or run `./aws-enumerator enum` to record the caller identity"))
+		os.Exit(1)
+	}
+	if caller.Type == analyze.PRINCIPAL_ROOT {
+		fmt.Println(utils.Green("Message: "), utils.Yellow("Root user credentials, every action is already allowed"))
+		return
+	}
+	start, ok := model.Lookup(caller)
+	if !ok {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("The principal"), utils.Red(caller.ARN), utils.Yellow("is not in the stored IAM results"))
+		os.Exit(1)
+	}
+
+	for _, missing := range model.Missing {
+		fmt.Println(utils.Yellow("Warning:"), utils.Yellow("No document for"), missing+utils.Yellow(", its permissions are not analyzed"))
+	}
+
+	report := analyze.AnalyzePrivesc(model, start)
+	saveAnalysis(analyze.PRIVESC_FILE, report)
+
+	if report.Admin {
+		fmt.Println(utils.Green("Message: "), utils.Green(report.Principal), utils.Yellow("already has full administrative access"))
+		return
+	}
+	for i, path := range report.Paths {
+		level := utils.Yellow("pivot")
+		if path.Admin {
+			level = utils.Red("admin")
+		}
+		conditional := ""
+		if path.Conditional {
+			conditional = utils.Yellow(" (conditional)")
+		}
+		fmt.Printf("%s %d: %s %s%s\n", utils.Green("Path"), i+1, level, path.Target, conditional)
+		for j, step := range path.Steps {
+			fmt.Printf("   %d. %s  %s -> %s  (%s)\n", j+1, utils.Yellow(step.Technique), step.From, step.To, strings.Join(step.Actions, ", "))
+			fmt.Printf("      %s\n", step.Description)
+		}
+	}
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Escalation paths of"), utils.Green(report.Principal)+utils.Yellow(":"), utils.Green(len(report.Paths)), utils.Yellow("see"), utils.Yellow(filepath.Join(utils.FILEPATH, analyze.PRIVESC_FILE)))
+}
+
+// analyzeExposure lists the resources of the stored results exposed to the internet, most severe first
+func analyzeExposure() {
+	if len(servicemaster.StoredRegions()) == 0 {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("No results found in"), utils.Red(utils.FILEPATH))
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Run `./aws-enumerator enum -services ec2,rds,s3,lambda,apigateway,apigatewayv2,ecr,elb,elbv2,sts -regions all` first"))
+		os.Exit(1)
+	}
+
+	report := analyze.AnalyzeExposure()
+	saveAnalysis(analyze.EXPOSURE_FILE, report)
+
+	for _, missing := range report.Missing {
+		fmt.Println(utils.Yellow("Warning:"), utils.Yellow("No stored results for"), missing+utils.Yellow(", its checks are skipped"))
+	}
+	counts := make(map[string]int)
+	for i, finding := range report.Findings {
+		counts[finding.Severity]++
+		severity := fmt.Sprintf("%-8s", finding.Severity)
+		switch finding.Severity {
+		case analyze.SEVERITY_CRITICAL, analyze.SEVERITY_HIGH:
+			severity = utils.Red(severity)
+		default:
+			severity = utils.Yellow(severity)
+		}
+		fmt.Printf("%3d. %s %s/%s %s\n", i+1, severity, finding.Region, finding.Service, finding.Resource)
+		fmt.Printf("     %s\n", finding.Description)
+	}
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Exposure findings:"), utils.Red(counts[analyze.SEVERITY_CRITICAL]), utils.Yellow("critical,"), utils.Red(counts[analyze.SEVERITY_HIGH]), utils.Yellow("high,"), counts[analyze.SEVERITY_MEDIUM], utils.Yellow("medium,"), counts[analyze.SEVERITY_LOW], utils.Yellow("low, see"), utils.Yellow(filepath.Join(utils.FILEPATH, analyze.EXPOSURE_FILE)))
+}
+
+// storedCallerARN returns the caller identity recorded by the enumeration (manifest.json, then sts:GetCallerIdentity)
+// or by analyze-self
+func storedCallerARN() string {
+	if manifest, err := servicemaster.LoadManifest(utils.FILEPATH); err == nil && manifest.Arn != "" {
+		return manifest.Arn
+	}
+	if results, err := servicemaster.LoadResults(utils.GLOBAL_REGION, "sts", false); err == nil {
+		var identity struct{ Arn string }
+		if json.Unmarshal(results["GetCallerIdentity"], &identity) == nil && identity.Arn != "" {
+			return identity.Arn
+		}
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(utils.FILEPATH, analyze.SELF_FILE)); err == nil {
+		var self analyze.SelfReport
+		if json.Unmarshal(data, &self) == nil {
+			return self.Principal.ARN
+		}
+	}
+	return ""
+}
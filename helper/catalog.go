@@ -0,0 +1,69 @@
+package helper
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/threatroute66/aws-enumerator/servicestructs"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// loadRegistry loads the catalog (with the override file, if any) and resolves it against the SDK clients
+func loadRegistry(catalogFile string) (*servicestructs.Registry, []error, error) {
+	catalog, err := servicestructs.LoadCatalog(catalogFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	registry, problems := servicestructs.NewRegistry(catalog)
+	return registry, problems, nil
+}
+
+// mustLoadRegistry loads the catalog for a command sending requests, exits with every problem of the catalog
+func mustLoadRegistry(catalogFile string) *servicestructs.Registry {
+	registry, problems, err := loadRegistry(catalogFile)
+	if err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("Unable to load the catalog"))
+		fmt.Println(utils.Red("Trace:"), utils.Yellow(err))
+		os.Exit(1)
+	}
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Println(utils.Red("Error:"), utils.Yellow(problem))
+		}
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Correct the catalog, use `./aws-enumerator catalog validate` to check it"))
+		os.Exit(1)
+	}
+	return registry
+}
+
+// HandleCatalogCommand runs the catalog subcommands
+func HandleCatalogCommand(command string, catalogFile *string) {
+	switch command {
+	case "validate":
+		validateCatalog(*catalogFile)
+	default:
+		fmt.Fprint(os.Stderr, Cloudrider_catalog_help)
+		os.Exit(1)
+	}
+}
+
+// validateCatalog reports every problem of the catalog without sending any request
+func validateCatalog(catalogFile string) {
+	registry, problems, err := loadRegistry(catalogFile)
+	if err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("Unable to load the catalog"))
+		fmt.Println(utils.Red("Trace:"), utils.Yellow(err))
+		os.Exit(1)
+	}
+
+	for _, problem := range problems {
+		fmt.Println(utils.Red("Error:"), utils.Yellow(problem))
+	}
+
+	services, apicalls := registry.Count()
+	if len(problems) > 0 {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("Catalog has"), utils.Red(len(problems)), utils.Yellow("problems"))
+		os.Exit(1)
+	}
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Catalog is valid:"), utils.Green(services), utils.Yellow("services,"), utils.Green(apicalls), utils.Yellow("API calls"))
+}
@@ -0,0 +1,110 @@
+package helper
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/analyze"
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// HandleDiffCommand compares the results of two runs: resources added, removed or changed and permission flips
+func HandleDiffCommand(runs []string, services, output *string) {
+	if len(runs) != 2 {
+		Diff.Usage()
+		os.Exit(1)
+	}
+	runA, runB := runDirectory(runs[0]), runDirectory(runs[1])
+	describeRun(runA)
+	describeRun(runB)
+
+	report, err := analyze.DiffRuns(runA, runB, splitList(*services))
+	if err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow(err))
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Pass two run directories, e.g. enum-results/20240131-142500 or 20240131-142500"))
+		os.Exit(1)
+	}
+
+	added, removed, changed := 0, 0, 0
+	for _, call := range report.Calls {
+		fmt.Printf("%s %s/%s", utils.Yellow(call.ApiCall), call.Region, call.Service)
+		if call.Missing != "" {
+			fmt.Printf("  %s\n", utils.Yellow("no stored result in "+call.Missing))
+			continue
+		}
+		fmt.Printf("  %s %s %s\n", utils.Green(fmt.Sprintf("+%d", len(call.Added))), utils.Red(fmt.Sprintf("-%d", len(call.Removed))), utils.Yellow(fmt.Sprintf("~%d", len(call.Changed))))
+		for _, resource := range call.Added {
+			fmt.Println("  ", utils.Green("+"), resource)
+		}
+		for _, resource := range call.Removed {
+			fmt.Println("  ", utils.Red("-"), resource)
+		}
+		for _, change := range call.Changed {
+			fmt.Println("  ", utils.Yellow("~"), change.Resource, utils.Yellow("("+strings.Join(change.Fields, ", ")+")"))
+		}
+		added, removed, changed = added+len(call.Added), removed+len(call.Removed), changed+len(call.Changed)
+	}
+
+	if len(report.Permissions) > 0 {
+		fmt.Printf("\n%s\n", utils.Yellow("Permissions flipped:"))
+		for _, flip := range report.Permissions {
+			fmt.Printf("   %s %s  %s -> %s\n", flip.Action, flip.Region, permissionColor(flip.Before), permissionColor(flip.After))
+		}
+	}
+
+	if *output != "" {
+		if err := ioutil.WriteFile(*output, []byte(utils.PackResponse(report)), 0644); err != nil {
+			fmt.Println(utils.Red("Error:"), utils.Yellow(err))
+			os.Exit(1)
+		}
+	}
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Resources:"), utils.Green(added), utils.Yellow("added,"), utils.Red(removed), utils.Yellow("removed,"), changed, utils.Yellow("changed, permissions flipped:"), len(report.Permissions))
+}
+
+// runDirectory accepts a run directory, or the name of a run under utils.RESULTS_ROOT
+func runDirectory(run string) string {
+	if _, err := os.Stat(run); os.IsNotExist(err) {
+		if _, err := os.Stat(filepath.Join(utils.RESULTS_ROOT, run)); err == nil {
+			return filepath.Join(utils.RESULTS_ROOT, run)
+		}
+	}
+	return run
+}
+
+// describeRun prints the identity and the start time recorded in the manifest of a run
+func describeRun(dir string) {
+	manifest, err := servicemaster.LoadManifest(dir)
+	if err != nil {
+		fmt.Printf("%s %s ( no manifest )%s\n", utils.Green("Info:"), utils.Yellow(dir), utils.Reset())
+		return
+	}
+	fmt.Printf("%s %s %s %s%s\n", utils.Green("Info:"), utils.Yellow(dir), manifest.Arn, manifest.Start.Format("2006-01-02 15:04:05"), utils.Reset())
+}
+
+func permissionColor(status string) string {
+	switch status {
+	case servicemaster.PERMISSION_ALLOWED:
+		return utils.Green(status)
+	case analyze.PERMISSION_UNTESTED:
+		return utils.Yellow(status)
+	}
+	return utils.Red(status)
+}
+
+// ParseWithArgs parses the flags placed before, between or after the positional arguments and returns the arguments
+func ParseWithArgs(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flags.Parse(args)
+		if flags.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
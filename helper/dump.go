@@ -0,0 +1,134 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// dumpEntry is a single stored API call response (or error) of a service
+type dumpEntry struct {
+	ApiCall string
+	Body    json.RawMessage
+}
+
+// DumpInfo lists and prints the results saved by the enum command
+func DumpInfo(services, regions *string, print *bool, filter *string, errors *bool) {
+	if *services == "" {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("No services specified"))
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Provide services, use `./aws-enumerator dump -h` command"))
+		os.Exit(1)
+	}
+
+	wantedRegions := servicemaster.StoredRegions()
+	if *regions != "all" && *regions != "" {
+		wantedRegions = splitList(*regions)
+	}
+
+	if len(wantedRegions) == 0 {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("No enumeration results found in"), utils.Red(utils.FILEPATH))
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Run enumeration first, use `./aws-enumerator enum -h` command"))
+		os.Exit(1)
+	}
+
+	for _, region := range wantedRegions {
+		wantedServices := splitList(*services)
+		if *services == "all" {
+			wantedServices = servicemaster.StoredServices(region, *errors)
+		}
+
+		for _, svc := range wantedServices {
+			entries, err := loadDumpEntries(region, svc, *errors)
+			if err != nil {
+				// regional folders only contain the services that were enumerated there
+				if *services != "all" && *regions != "all" {
+					fmt.Println(utils.Red("Error:"), utils.Yellow("No stored results for"), utils.Red(svc+" ("+region+")"))
+				}
+				continue
+			}
+
+			entries = filterDumpEntries(entries, *filter)
+			if len(entries) == 0 {
+				continue
+			}
+
+			title := strings.ToUpper(svc) + " (" + region + ")"
+			if *errors {
+				fmt.Println(utils.Green("Message:"), utils.Yellow("Failed API calls for"), utils.Red(title)+utils.Yellow(":"))
+			} else {
+				fmt.Println(utils.Green("Message:"), utils.Yellow("Successful API calls for"), utils.Green(title)+utils.Yellow(":"))
+			}
+
+			for _, entry := range entries {
+				if !*print {
+					if *errors {
+						fmt.Println("   ", utils.Yellow(entry.ApiCall), utils.Red(errorSummary(entry.Body)))
+					} else {
+						fmt.Println("   ", utils.Yellow(entry.ApiCall))
+					}
+					continue
+				}
+				fmt.Println(utils.Green(entry.ApiCall) + utils.Yellow(":"))
+				fmt.Println(prettyJSON(entry.Body))
+			}
+			fmt.Println()
+		}
+	}
+}
+
+// loadDumpEntries returns the stored responses (or errors) of a service sorted by API call
+func loadDumpEntries(region, svc string, errors bool) ([]dumpEntry, error) {
+	results, err := servicemaster.LoadResults(region, svc, errors)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []dumpEntry
+	for name, body := range results {
+		entries = append(entries, dumpEntry{ApiCall: name, Body: body})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ApiCall < entries[j].ApiCall })
+	return entries, nil
+}
+
+// filterDumpEntries keeps the entries whose API call name starts with prefix
+func filterDumpEntries(entries []dumpEntry, prefix string) []dumpEntry {
+	if prefix == "" {
+		return entries
+	}
+
+	var filtered []dumpEntry
+	for _, entry := range entries {
+		if strings.HasPrefix(strings.ToLower(entry.ApiCall), strings.ToLower(prefix)) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// errorSummary returns the class and AWS error code of a stored error
+func errorSummary(raw json.RawMessage) string {
+	var callErr servicemaster.CallError
+	if err := json.Unmarshal(raw, &callErr); err != nil || callErr.Class == "" {
+		return ""
+	}
+	if callErr.Code == "" {
+		return callErr.Class
+	}
+	return callErr.Class + " (" + callErr.Code + ")"
+}
+
+// prettyJSON re-indents a raw json value for terminal output
+func prettyJSON(raw json.RawMessage) string {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	return utils.PackResponse(value)
+}
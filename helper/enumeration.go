@@ -1,87 +1,179 @@
 package helper
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/threatroute66/aws-enumerator/utils"
 	"github.com/threatroute66/aws-enumerator/servicemaster"
 	"github.com/threatroute66/aws-enumerator/servicestructs"
 )
 
-// SetEnumerationPipeline sets up credentials and runs servicemaster enumeration
-func SetEnumerationPipeline(services, speed, profile *string) {
-	var profileName string
-	if profile != nil {
-		profileName = *profile
+// EnumerationOptions are the flags of the enum command
+type EnumerationOptions struct {
+	Services        string // all, or comma-separated list
+	Speed           string // slow, normal, fast
+	Profile         string
+	Regions         string // all, or comma-separated list, empty for the region of the profile
+	MaxPages        int
+	Concurrency     int
+	S3MaxKeys       int
+	RPS             float64
+	AssumeRole      string // comma-separated role chain
+	ExternalID      string
+	RoleSessionName string
+	CatalogFile     string
+	Timeout         time.Duration
+	CallTimeout     time.Duration
+	Resume          bool
+	Output          string // run directory, empty for a new directory under utils.RESULTS_ROOT
+}
+
+// SetEnumerationPipeline sets up credentials and runs servicemaster enumeration, ctx cancels the running api calls
+func SetEnumerationPipeline(ctx context.Context, opts EnumerationOptions) {
+	// Load and validate the catalog of services and API calls before any network traffic
+	registry := mustLoadRegistry(opts.CatalogFile)
+	registry.SetS3MaxKeys(opts.S3MaxKeys)
+
+	cfg, identity, creds := loadAWSConfig(ctx, opts.Profile, opts.AssumeRole, opts.ExternalID, opts.RoleSessionName)
+
+	// Every run gets its own directory, -resume continues the latest one
+	outputDir := opts.Output
+	if outputDir == "" && opts.Resume {
+		outputDir = utils.LatestRun()
+	} else if outputDir == "" {
+		outputDir = utils.RunDirectory(time.Now())
+	}
+	utils.SetOutputDir(outputDir)
+	fmt.Printf("%s Output directory: %s%s\n", utils.Green("Info:"), utils.Yellow(outputDir), utils.Reset())
+
+	manifest := &servicemaster.Manifest{
+		Version:          utils.VERSION,
+		Account:          aws.ToString(identity.Account),
+		Arn:              aws.ToString(identity.Arn),
+		CredentialSource: creds.Source,
+		AssumedRole:      creds.RoleARN,
+	}
+	// a resumed run keeps the start time of the run it continues
+	if previous, err := servicemaster.LoadManifest(outputDir); err == nil && opts.Resume {
+		manifest.Start = previous.Start
 	}
 
-	// Load credentials using new credential management
-	if profileName != "" {
-		creds, err := utils.LoadCredentials(profileName)
-		if err != nil {
-			log.Fatalf("Failed to load credentials: %v", err)
-		}
+	// Get all AWS services of the wanted regions from servicestructs
+	allServices := servicestructs.GetServices(ctx, cfg, registry, splitList(opts.Regions))
 
-		// Set environment variables for servicemaster
-		os.Setenv("AWS_ACCESS_KEY_ID", creds.AccessKeyID)
-		os.Setenv("AWS_SECRET_ACCESS_KEY", creds.SecretAccessKey)
-		if creds.SessionToken != "" {
-			os.Setenv("AWS_SESSION_TOKEN", creds.SessionToken)
-		}
-		if creds.Region != "" {
-			os.Setenv("AWS_REGION", creds.Region)
-		}
+	// Parse services - convert "all" or "iam,s3,sts" to string slice
+	wantedServices := splitList(opts.Services)
+
+	// Convert speed string to int, explicit -concurrency / -rps override the speed preset
+	options := servicemaster.Options{
+		Speed:       convertSpeedToInt(opts.Speed),
+		MaxPages:    opts.MaxPages,
+		Concurrency: opts.Concurrency,
+		RPS:         opts.RPS,
+		CallTimeout: opts.CallTimeout,
+		Resume:      opts.Resume,
+	}
+
+	fmt.Printf("%s Starting enumeration with services: %s, speed: %s%s\n",
+		utils.Green("Info:"), opts.Services, opts.Speed, utils.Reset())
+	if opts.Regions != "" {
+		fmt.Printf("%s Regions: %s%s\n", utils.Green("Info:"), utils.Yellow(opts.Regions), utils.Reset())
+	}
+
+	// -timeout bounds the whole enumeration, the results gathered until then are saved
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+		fmt.Printf("%s Timeout: %s%s\n", utils.Green("Info:"), utils.Yellow(opts.Timeout.String()), utils.Reset())
+	}
+
+	// Call the actual servicemaster enumeration - THIS IS THE KEY LINE
+	servicemaster.ServiceCall(ctx, allServices, wantedServices, options, manifest)
+
+	// Flat inventory of the resources found in the stored results
+	saveInventory()
+}
+
+// loadAWSConfig resolves the credentials (profile, environment, .env, -assume-role chain), builds the SDK config
+// and validates it with sts:GetCallerIdentity, exits on failure. ctx cancels the calls made to resolve them.
+func loadAWSConfig(ctx context.Context, profileName, assumeRole, externalID, roleSessionName string) (aws.Config, *sts.GetCallerIdentityOutput, *utils.AWSCredentials) {
+	// Load credentials using new credential management
+	creds, err := utils.LoadCredentials(ctx, profileName)
+	if profileName != "" && err != nil {
+		log.Fatalf("Failed to load credentials: %v", err)
+	}
 
-		fmt.Printf("%s Using credentials from: profile%s\n", 
-			utils.Green("Info:"), utils.Reset())
-		if creds.Region != "" {
-			fmt.Printf("%s Region: %s%s\n", 
-				utils.Green("Info:"), utils.Yellow(creds.Region), utils.Reset())
+	// Assume the roles of -assume-role with the loaded credentials
+	if roles := splitList(assumeRole); len(roles) > 0 {
+		if err != nil || creds == nil {
+			log.Fatalf("Failed to load credentials to assume %s: %v", assumeRole, err)
 		}
-	} else {
-		// Load from env/env_file for backward compatibility
-		creds, err := utils.LoadCredentials("")
-		if err == nil && creds != nil {
-			fmt.Printf("%s Using credentials from: %s%s\n", 
-				utils.Green("Info:"), utils.Yellow(creds.Source), utils.Reset())
-			if creds.Region != "" {
-				fmt.Printf("%s Region: %s%s\n", 
-					utils.Green("Info:"), utils.Yellow(creds.Region), utils.Reset())
-			}
+		creds, err = utils.AssumeRoleChain(ctx, creds, roles, externalID, roleSessionName)
+		if err != nil {
+			log.Fatalf("Failed to assume role: %v", err)
 		}
 	}
 
-	// Check credentials are available
-	if !servicemaster.CheckAWSCredentials() {
-		log.Fatalf("AWS credentials not found or invalid")
+	if err != nil || creds == nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("AWS credentials not found"))
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Use -profile, set AWS_ACCESS_KEY_ID / AWS_PROFILE or run `./aws-enumerator cred -h`"))
+		fmt.Println(utils.Red("Trace:"), utils.Yellow(err))
+		os.Exit(1)
 	}
 
-	// Get all AWS services from servicestructs
-	allServices := servicestructs.GetServices()
+	fmt.Printf("%s Using credentials from: %s%s\n",
+		utils.Green("Info:"), utils.Yellow(creds.Source), utils.Reset())
+	if creds.RoleARN != "" {
+		fmt.Printf("%s Assumed role: %s%s\n",
+			utils.Green("Info:"), utils.Yellow(creds.RoleARN), utils.Reset())
+	}
+	if creds.Region != "" {
+		fmt.Printf("%s Region: %s%s\n",
+			utils.Green("Info:"), utils.Yellow(creds.Region), utils.Reset())
+	}
 
-	// Parse services - convert "all" or "iam,s3,sts" to string slice
-	var wantedServices []string
-	if *services == "all" {
-		wantedServices = []string{"all"}
-	} else {
-		wantedServices = strings.Split(*services, ",")
-		// Trim whitespace from each service
-		for i := range wantedServices {
-			wantedServices[i] = strings.TrimSpace(wantedServices[i])
-		}
+	// The resolved credentials are handed to the SDK clients, the process environment stays untouched
+	cfg, err := utils.NewAWSConfig(ctx, creds)
+	if err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("Unable to load SDK config,"))
+		fmt.Println(utils.Red("Trace:"), utils.Yellow(err))
+		os.Exit(1)
 	}
 
-	// Convert speed string to int
-	speedInt := convertSpeedToInt(*speed)
+	// Check credentials are valid
+	identity := servicemaster.CheckAWSCredentials(ctx, cfg)
+	return cfg, identity, creds
+}
 
-	fmt.Printf("%s Starting enumeration with services: %s, speed: %s%s\n",
-		utils.Green("Info:"), *services, *speed, utils.Reset())
+// UseResults points the commands reading the results at the run directory, the latest run by default
+func UseResults(dir string) {
+	if dir == "" {
+		dir = utils.LatestRun()
+	}
+	utils.SetOutputDir(dir)
+}
 
-	// Call the actual servicemaster enumeration - THIS IS THE KEY LINE
-	servicemaster.ServiceCall(allServices, wantedServices, speedInt)
+// splitList converts "a,b, c" to a trimmed string slice, an empty string to nil
+func splitList(list string) []string {
+	if strings.TrimSpace(list) == "" {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // convertSpeedToInt converts speed string to int (based on original logic)
@@ -98,29 +190,34 @@ func convertSpeedToInt(speed string) int {
 	}
 }
 
-// HandleProfilesCommand lists available AWS profiles
+// HandleProfilesCommand lists available AWS profiles with their source type and region
 func HandleProfilesCommand() {
-	profiles, err := utils.ListAvailableProfiles()
+	profiles, err := utils.ListProfiles()
 	if err != nil {
 		fmt.Printf("%s Failed to list profiles: %v%s\n", utils.Red("Error:"), err, utils.Reset())
 		return
 	}
 
 	if len(profiles) == 0 {
-		fmt.Printf("%s No AWS profiles found in ~/.aws/credentials%s\n", utils.Yellow("Info:"), utils.Reset())
+		fmt.Printf("%s No AWS profiles found in ~/.aws/credentials or ~/.aws/config%s\n", utils.Yellow("Info:"), utils.Reset())
 		fmt.Printf("%s To create profiles, use: aws configure --profile <profile-name>%s\n", utils.Green("Tip:"), utils.Reset())
 		return
 	}
 
+	width := len("PROFILE")
+	for _, profile := range profiles {
+		if len(profile.Name) > width {
+			width = len(profile.Name)
+		}
+	}
+
 	fmt.Printf("%s Available AWS profiles:%s\n", utils.Green("Info:"), utils.Reset())
+	fmt.Printf("  %-*s  %-18s  %s\n", width, "PROFILE", "SOURCE", "REGION")
 	for _, profile := range profiles {
-		fmt.Printf("  • %s%s%s\n", utils.Yellow(""), profile, utils.Reset())
+		region := profile.Region
+		if region == "" {
+			region = "-"
+		}
+		fmt.Printf("  %s  %-18s  %s\n", utils.Yellow(fmt.Sprintf("%-*s", width, profile.Name)), profile.Source, region)
 	}
 }
-
-// DumpInfo - preserve original dump functionality
-func DumpInfo(services *string, print *bool, filter *string, errors *bool) {
-	// TODO: Copy the original DumpInfo implementation here
-	fmt.Printf("%s Dumping info for services: %s%s\n", 
-		utils.Green("Info:"), *services, utils.Reset())
-}
\ No newline at end of file
@@ -0,0 +1,43 @@
+package helper
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/analyze"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// HandleFindingsCommand exports the findings of the saved analysis reports as JSON, CSV or SARIF
+func HandleFindingsCommand(format, output *string) {
+	findings, sources := analyze.LoadFindings()
+	if len(sources) == 0 {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("No analysis report found in"), utils.Red(utils.FILEPATH))
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Run `./aws-enumerator analyze exposure`, `./aws-enumerator analyze privesc` or `./aws-enumerator scan-secrets` first"))
+		os.Exit(1)
+	}
+
+	var exported bytes.Buffer
+	if err := analyze.WriteFindings(&exported, strings.ToLower(*format), findings); err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow(err))
+		os.Exit(1)
+	}
+
+	filename := *output
+	if filename == "-" {
+		os.Stdout.Write(exported.Bytes())
+		return
+	}
+	if filename == "" {
+		filename = filepath.Join(utils.FILEPATH, "findings."+strings.ToLower(*format))
+	}
+	if err := ioutil.WriteFile(filename, exported.Bytes(), 0644); err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow(err))
+		os.Exit(1)
+	}
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Findings:"), utils.Green(len(findings)), utils.Yellow("from"), strings.Join(sources, ", ")+utils.Yellow(", see"), utils.Yellow(filename))
+}
@@ -2,6 +2,9 @@ package helper
 
 import (
 	"flag"
+	"time"
+
+	"github.com/threatroute66/aws-enumerator/utils"
 )
 
 var (
@@ -12,10 +15,48 @@ var (
 	AWS_session_token     *string
 	Services_enum         *string
 	Services_dump         *string
+	Regions_enum          *string
+	Regions_dump          *string
 	Speed                 *string
+	Max_pages             *int
+	S3_max_keys           *int
+	Concurrency           *int
+	RPS                   *float64
+	Timeout               *time.Duration
+	Call_timeout          *time.Duration
+	Resume                *bool
+	Output_enum           *string
+	Results_dump          *string
+	Results_self          *string
+	Results_analyze       *string
+	Results_secrets       *string
+	Results_findings      *string
+	Services_diff         *string
+	Output_diff           *string
+	Type_inventory        *string
+	Tag_inventory         *string
+	Region_inventory      *string
+	Format_inventory      *string
+	Results_inventory     *string
 	Print                 *bool
 	Filter                *string
 	Errors_dump           *bool
+	Assume_role           *string
+	External_id           *string
+	Role_session_name     *string
+	Catalog_enum          *string
+	Catalog_file          *string
+	Profile_self          *string
+	Assume_role_self      *string
+	External_id_self      *string
+	Role_session_self     *string
+	Catalog_self          *string
+	All_self              *bool
+	Principal_analyze     *string
+	Rules_secrets         *string
+	Services_secrets      *string
+	Format_findings       *string
+	Output_findings       *string
 	
 	// New profile variable
 	Profile               *string
@@ -24,6 +65,13 @@ var (
 	Cred = flag.NewFlagSet("cred", flag.ExitOnError)
 	Enum = flag.NewFlagSet("enum", flag.ExitOnError)
 	Dump = flag.NewFlagSet("dump", flag.ExitOnError)
+	Catalog = flag.NewFlagSet("catalog", flag.ExitOnError)
+	AnalyzeSelf = flag.NewFlagSet("analyze-self", flag.ExitOnError)
+	Analyze = flag.NewFlagSet("analyze", flag.ExitOnError)
+	ScanSecrets = flag.NewFlagSet("scan-secrets", flag.ExitOnError)
+	Findings = flag.NewFlagSet("findings", flag.ExitOnError)
+	Diff = flag.NewFlagSet("diff", flag.ExitOnError)
+	Inventory = flag.NewFlagSet("inventory", flag.ExitOnError)
 )
 
 func init() {
@@ -36,11 +84,63 @@ func init() {
 	// Enum command flags
 	Services_enum = Enum.String("services", "", "Services to enumerate (e.g., all, iam,s3,sts)")
 	Speed = Enum.String("speed", "normal", "Enumeration speed: slow, normal, fast")
-	Profile = Enum.String("profile", "", "AWS profile to use from ~/.aws/credentials")
+	Regions_enum = Enum.String("regions", "", "Regions to enumerate (e.g., all, us-east-1,eu-west-1), defaults to the profile region")
+	Concurrency = Enum.Int("concurrency", 0, "Maximum number of API calls running at the same time (default: from -speed)")
+	RPS = Enum.Float64("rps", 0, "Maximum requests per second for the whole run (default: from -speed)")
+	Timeout = Enum.Duration("timeout", 0, "Maximum duration of the whole enumeration, e.g. 30m (0 = none)")
+	Call_timeout = Enum.Duration("call-timeout", 0, "Maximum duration of a single API request, e.g. 30s (0 = none)")
+	Resume = Enum.Bool("resume", false, "Continue the interrupted enumeration, the pages of the journal are not requested again")
+	Output_enum = Enum.String("output", "", "Directory of the results (default: enum-results/<start time>, with -resume the latest run)")
+	Max_pages = Enum.Int("max-pages", 0, "Maximum number of pages fetched per API call (0 = unlimited)")
+	S3_max_keys = Enum.Int("s3-max-keys", 0, "Maximum number of object keys listed per S3 bucket (0 = no object listing)")
+	Profile = Enum.String("profile", "", "AWS profile to use from ~/.aws/credentials or ~/.aws/config (default: $AWS_PROFILE)")
+	Catalog_enum = Enum.String("catalog", "", "JSON or YAML (.yaml, .yml) file with services / API calls merged on top of the built-in catalog")
+	Assume_role = Enum.String("assume-role", "", "Role ARN to assume, comma-separated ARNs are assumed one after another")
+	External_id = Enum.String("external-id", "", "External ID of the (last) role to assume")
+	Role_session_name = Enum.String("role-session-name", utils.DEFAULT_ROLE_SESSION_NAME, "Session name of the assumed roles")
 
 	// Dump command flags
-	Services_dump = Dump.String("services", "", "Services to dump")
-	Print = Dump.Bool("print", false, "Print results")
-	Filter = Dump.String("filter", "", "Filter API calls")
-	Errors_dump = Dump.Bool("errors", false, "Show errors")
-}
\ No newline at end of file
+	Services_dump = Dump.String("services", "", "Services to dump (e.g., all, iam,s3,sts)")
+	Regions_dump = Dump.String("regions", "all", "Regions to dump (e.g., all, global,us-east-1)")
+	Print = Dump.Bool("print", false, "Print stored API call responses")
+	Filter = Dump.String("filter", "", "Filter API calls by name prefix")
+	Errors_dump = Dump.Bool("errors", false, "Show failed API calls")
+	Results_dump = Dump.String("results", "", "Directory of the enumeration run to read (default: the latest run in enum-results)")
+
+	// Catalog command flags
+	Catalog_file = Catalog.String("catalog", "", "JSON or YAML (.yaml, .yml) file with services / API calls merged on top of the built-in catalog")
+
+	// Analyze-self command flags
+	Profile_self = AnalyzeSelf.String("profile", "", "AWS profile to use from ~/.aws/credentials or ~/.aws/config (default: $AWS_PROFILE)")
+	Assume_role_self = AnalyzeSelf.String("assume-role", "", "Role ARN to assume, comma-separated ARNs are assumed one after another")
+	External_id_self = AnalyzeSelf.String("external-id", "", "External ID of the (last) role to assume")
+	Role_session_self = AnalyzeSelf.String("role-session-name", utils.DEFAULT_ROLE_SESSION_NAME, "Session name of the assumed roles")
+	Catalog_self = AnalyzeSelf.String("catalog", "", "JSON or YAML (.yaml, .yml) file with services / API calls merged on top of the built-in catalog")
+	All_self = AnalyzeSelf.Bool("all", false, "Also print the actions that are not granted")
+	Results_self = AnalyzeSelf.String("results", "", "Directory of the enumeration run the report is saved to (default: the latest run in enum-results)")
+
+	// Analyze command flags
+	Principal_analyze = Analyze.String("principal", "", "ARN of the principal to analyze (default: the identity of the enumeration)")
+	Results_analyze = Analyze.String("results", "", "Directory of the enumeration run to read (default: the latest run in enum-results)")
+
+	// Scan-secrets command flags
+	Rules_secrets = ScanSecrets.String("rules", "", "JSON file with secret rules merged on top of the built-in ruleset")
+	Results_secrets = ScanSecrets.String("results", "", "Directory of the enumeration run to read (default: the latest run in enum-results)")
+	Services_secrets = ScanSecrets.String("services", "all", "Services whose results are scanned (e.g., all, lambda,ec2,ecs)")
+
+	// Findings command flags
+	Format_findings = Findings.String("format", "json", "Export format: json, csv, sarif")
+	Output_findings = Findings.String("output", "", "File to write, - for stdout (default: findings.<format> in the results directory)")
+	Results_findings = Findings.String("results", "", "Directory of the enumeration run to read (default: the latest run in enum-results)")
+
+	// Diff command flags
+	Services_diff = Diff.String("services", "all", "Services whose results are compared (e.g., all, iam,ec2)")
+	Output_diff = Diff.String("output", "", "JSON file to write the diff report to")
+
+	// Inventory command flags
+	Type_inventory = Inventory.String("type", "", "Resource types to list (e.g., ec2:instance,iam:role, or a service: s3)")
+	Tag_inventory = Inventory.String("tag", "", "Tags of the resources to list, Key or Key=Value (e.g., Environment=prod,Owner)")
+	Region_inventory = Inventory.String("region", "", "Regions of the resources to list (e.g., global,us-east-1)")
+	Format_inventory = Inventory.String("format", "table", "Output format: table, jsonl")
+	Results_inventory = Inventory.String("results", "", "Directory of the enumeration run to read (default: the latest run in enum-results)")
+}
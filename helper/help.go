@@ -9,8 +9,43 @@ Options:
         Services to enumerate: all, or comma-separated list (e.g., iam,s3,sts)
   -speed string
         Enumeration speed: slow, normal, fast (default "normal")
+        slow: 5 concurrent calls, 5 req/s | normal: 20, 20 req/s | fast: 50, 50 req/s
+  -concurrency int
+        Maximum number of API calls running at the same time (overrides -speed)
+  -rps float
+        Maximum requests per second for the whole run (overrides -speed)
+  -regions string
+        Regions to enumerate: all, or comma-separated list (e.g., us-east-1,eu-west-1)
+        Defaults to the region of the profile / environment
+  -max-pages int
+        Maximum number of pages fetched per API call, a capped result keeps its next page token (default 0 = unlimited)
+  -timeout duration
+        Stop the enumeration after this duration (e.g. 30m), the results gathered so far are saved (default 0 = none)
+  -call-timeout duration
+        Give up a single API request after this duration (e.g. 30s), stored with the "timeout" error class (default 0 = none)
+  -output string
+        Directory of the results (default: enum-results/<start time>, e.g. enum-results/20240131-142500)
+        A manifest.json records the identity, credential source, regions, services and call counts of the run
+  -resume
+        Continue an interrupted enumeration: the pages recorded in the journal.jsonl of the run directory are
+        replayed instead of being requested again, failed API calls are retried (default: the latest run)
+  -s3-max-keys int
+        List up to this many object keys per S3 bucket with ListObjectsV2 (default 0 = no object listing)
+  -catalog string
+        JSON or YAML (.yaml, .yml) file with services / API calls merged on top of the built-in catalog
+        ( add calls, change default inputs or disable calls / services with "disabled": true )
   -profile string
-        AWS profile to use from ~/.aws/credentials
+        AWS profile to use from ~/.aws/credentials or ~/.aws/config (default: $AWS_PROFILE)
+        Static keys, role_arn / source_profile / external_id, credential_process and sso_* profiles
+        ( run "aws sso login" first ) are resolved like the AWS CLI does
+        AWS_SHARED_CREDENTIALS_FILE and AWS_CONFIG_FILE override the file locations
+  -assume-role string
+        Role ARN to assume with the loaded credentials
+        Comma-separated ARNs are assumed one after another (role chaining)
+  -external-id string
+        External ID of the role to assume (used for the last role of a chain)
+  -role-session-name string
+        Session name of the assumed roles (default "aws-enumerator")
 
 Examples:
   # Use default credentials (env vars or .env file)
@@ -21,6 +56,39 @@ Examples:
 
   # Use specific services with profile
   ./aws-enumerator enum -services iam,s3,sts -profile production
+
+  # Assume a role of another account
+  ./aws-enumerator enum -services all -assume-role arn:aws:iam::123456789012:role/audit -external-id abc123
+
+  # Chain roles: the second role is assumed with the credentials of the first one
+  ./aws-enumerator enum -services iam -assume-role arn:aws:iam::111111111111:role/jump,arn:aws:iam::222222222222:role/audit
+
+  # Enumerate every enabled region of the account
+  ./aws-enumerator enum -services all -regions all
+
+  # Enumerate specific regions
+  ./aws-enumerator enum -services ec2,lambda -regions us-east-1,eu-west-1
+
+  # Stay well below the AWS API throttling limits
+  ./aws-enumerator enum -services all -concurrency 4 -rps 2
+
+  # Disable or add API calls for this engagement without a rebuild
+  ./aws-enumerator enum -services all -catalog engagement.json
+
+  # Fetch at most 20 pages per paginated API call
+  ./aws-enumerator enum -services iam,lambda -max-pages 20
+
+  # Stop after 20 minutes, give up requests hanging for more than 30 seconds
+  ./aws-enumerator enum -services all -regions all -timeout 20m -call-timeout 30s
+
+  # Continue the enumeration after an interruption ( expired session, VPN drop, Ctrl-C )
+  ./aws-enumerator enum -services all -regions all -resume
+
+  # Keep the runs of an engagement together
+  ./aws-enumerator enum -services all -profile client-a -output engagements/client-a/run1
+
+  # Review the configuration of every bucket and list the first 100 keys of each
+  ./aws-enumerator enum -services s3 -s3-max-keys 100
 `
 
 const Cloudrider_cred_help = `
@@ -47,17 +115,198 @@ Usage: aws-enumerator dump [options]
 
 Options:
   -services string
-        Services to dump
+        Services to dump: all, or comma-separated list (e.g., iam,s3,sts)
+  -regions string
+        Regions to dump: all, or comma-separated list (e.g., global,us-east-1) (default "all")
   -print bool
-        Print results
+        Print the stored responses of the API calls
   -filter string
-        Filter API calls
+        Filter API calls by name prefix (e.g., GetA)
   -errors bool
-        Show errors
+        Show failed API calls instead of successful ones
+  -results string
+        Directory of the enumeration run to read (default: the latest run in enum-results)
 
 Examples:
   ./aws-enumerator dump -services all
+  ./aws-enumerator dump -services iam,s3,sts
   ./aws-enumerator dump -services iam -filter GetUser -print
+  ./aws-enumerator dump -services iam -errors -print
+  ./aws-enumerator dump -services lambda -regions eu-west-1 -print
+  ./aws-enumerator dump -services iam -results enum-results/20240131-142500
+`
+
+const Cloudrider_catalog_help = `
+Usage: aws-enumerator catalog validate [options]
+
+Checks the catalog of services and API calls against the SDK clients without sending any request:
+unknown services or API calls, inputs that don't match the input type, calls that are not read-only,
+paginators and dependency rules ( depends_on / foreach / params ).
+
+Options:
+  -catalog string
+        JSON or YAML (.yaml, .yml) file with services / API calls merged on top of the built-in catalog
+
+Examples:
+  ./aws-enumerator catalog validate
+  ./aws-enumerator catalog validate -catalog engagement.yaml
+`
+
+const Cloudrider_analyze_self_help = `
+Usage: aws-enumerator analyze-self [options]
+
+Reads the policies of the caller ( attached, inline and group policies, permissions boundary ) with the iam api
+and evaluates them offline: Allow / Deny, wildcards and NotAction / NotResource. Conditions are not evaluated,
+the actions they guard are reported as conditional. Service control policies and resource policies are not
+taken into account, an unreadable permissions boundary denies every action. The actions of the catalog and every action named in the policies are evaluated,
+the report is saved to analyze-self.json in the results directory.
+
+Options:
+  -profile string
+        AWS profile to use from ~/.aws/credentials or ~/.aws/config (default: $AWS_PROFILE)
+  -assume-role string
+        Role ARN to assume with the loaded credentials ( comma-separated ARNs for role chaining )
+  -external-id string
+        External ID of the role to assume (used for the last role of a chain)
+  -role-session-name string
+        Session name of the assumed roles (default "aws-enumerator")
+  -catalog string
+        JSON or YAML (.yaml, .yml) file with services / API calls merged on top of the built-in catalog
+  -all
+        Also print the actions that are not granted
+  -results string
+        Directory of the enumeration run the report is saved to (default: the latest run in enum-results)
+
+Examples:
+  ./aws-enumerator analyze-self -profile myprofile
+  ./aws-enumerator analyze-self -assume-role arn:aws:iam::123456789012:role/audit -all
+`
+
+const Cloudrider_analyze_help = `
+Usage: aws-enumerator analyze privesc|exposure [options]
+
+Analyses of the stored enumeration results, no request is sent. The reports are saved to the results directory.
+
+  privesc   Searches the IAM data ( GetAccountAuthorizationDetails, or the per principal iam calls ) for
+            privilege escalation paths of a principal: policy versions, attaching or putting policies,
+            access keys and login profiles of other users, group memberships, sts:AssumeRole on roles that
+            trust the principal, iam:PassRole with lambda / ec2 / cloudformation / glue / codebuild ...,
+            lambda:UpdateFunctionCode of functions running with a role. Paths are chained up to 4 steps,
+            the report is saved to analyze-privesc.json.
+
+  exposure  Lists the resources exposed to the internet, most severe first: security groups open to 0.0.0.0/0
+            or ::/0 ( all traffic, or sensitive ports ), publicly accessible RDS instances, public RDS / EBS
+            snapshots and AMIs, public S3 bucket policies and ACLs, Lambda function URLs without authentication
+            and public function policies, API Gateway methods / routes without authorizer, public ECR repository
+            policies, internet-facing load balancers. The report is saved to analyze-exposure.json.
+
+Options:
+  -principal string
+        ARN of the principal to analyze with privesc (default: the caller identity of the run manifest)
+  -results string
+        Directory of the enumeration run to read (default: the latest run in enum-results)
+
+Examples:
+  ./aws-enumerator enum -services iam,sts,lambda -regions all
+  ./aws-enumerator analyze privesc
+  ./aws-enumerator analyze privesc -principal arn:aws:iam::123456789012:user/bob
+  ./aws-enumerator enum -services ec2,rds,s3,lambda,apigateway,apigatewayv2,ecr,elb,elbv2,sts -regions all
+  ./aws-enumerator analyze exposure
+`
+
+const Cloudrider_scan_secrets_help = `
+Usage: aws-enumerator scan-secrets [options]
+
+Scans every stored response ( <results>/<region>/<service>.json ) for secrets: AWS access keys, private keys,
+JWTs, database connection URLs with credentials, password / token variables ( lambda environment, ecs task
+definitions, codebuild projects, ssm parameters, cloudformation parameters ...) and high entropy strings in
+secret-looking fields. EC2 user data is
+base64 decoded before the scan. Each hit lists the service, region, API call, JSON path and a redacted preview,
+the report is saved to scan-secrets.json in the results directory. No request is sent.
+
+Options:
+  -rules string
+        JSON file with secret rules merged on top of the built-in ruleset
+        ( rules with the same name are replaced, "disabled": true turns a rule off )
+  -services string
+        Services whose results are scanned (e.g., all, lambda,ec2,ecs) (default "all")
+  -results string
+        Directory of the enumeration run to read (default: the latest run in enum-results)
+
+Examples:
+  ./aws-enumerator scan-secrets
+  ./aws-enumerator scan-secrets -services lambda,ecs -rules engagement-rules.json
+`
+
+const Cloudrider_findings_help = `
+Usage: aws-enumerator findings [options]
+
+Exports the findings of the saved analysis reports ( analyze exposure, analyze privesc, scan-secrets ) in a single
+file, most severe first. Each finding has an ID, a title, a severity ( critical, high, medium, low ), the resource
+ARN, the service and region, the api call and JSON path of the evidence in the stored results, a description and
+a remediation. SARIF results point to the stored results file ( relative to the RESULTS base, the results
+directory ) and to the resource ARN as logical locations.
+
+Options:
+  -format string
+        Export format: json, csv, sarif (default "json")
+  -output string
+        File to write, - for stdout (default: findings.<format> in the results directory)
+  -results string
+        Directory of the enumeration run to read (default: the latest run in enum-results)
+
+Examples:
+  ./aws-enumerator analyze exposure && ./aws-enumerator findings -format sarif
+  ./aws-enumerator findings -format csv -output - > findings.csv
+`
+
+const Cloudrider_diff_help = `
+Usage: aws-enumerator diff [options] RUN_A RUN_B
+
+Compares the stored results of two runs ( run directories, or run names under enum-results ), no request is sent:
+  - resources added, removed or changed per service, region and API call. Resources are the items of the lists
+    of a response, identified by their ARN, ID or name; the items of dependent API calls by their input too.
+    Fields that change on their own ( last used dates ) are ignored, items sharing an identifier are numbered ( #2 ).
+  - API calls with a stored result in one run only ( failed, or not enumerated, in the other )
+  - permissions flipped between allowed and denied in a region ( permissions.json of both runs ), and the
+    permissions allowed or denied in one run only ( untested in the other )
+
+Options:
+  -services string
+        Services whose results are compared (e.g., all, iam,ec2) (default "all")
+  -output string
+        JSON file to write the diff report to
+
+Examples:
+  ./aws-enumerator diff 20240131-142500 20240214-093000
+  ./aws-enumerator diff -services iam,s3 enum-results/20240131-142500 enum-results/20240214-093000 -output diff.json
+`
+
+const Cloudrider_inventory_help = `
+Usage: aws-enumerator inventory [options]
+
+Lists the resources of the inventory ( resources.jsonl of the run ): type, ID, ARN, name, region, account, tags
+and the API call they were found in. The inventory is extracted from the lists of the stored responses at the
+end of the enumeration ( Reservations[].Instances[] for EC2 instances ); the tags returned by the dependent API
+calls ( GetBucketTagging, ListTags ... ) are added to their resource. For runs without an inventory it is
+extracted first. No request is sent. Filters take comma-separated values, a resource matches any of them.
+
+Options:
+  -type string
+        Resource types to list (e.g., ec2:instance,iam:role), or every type of a service (e.g., s3)
+  -tag string
+        Tags of the resources to list, Key or Key=Value (e.g., Environment=prod,Owner)
+  -region string
+        Regions of the resources to list (e.g., global,us-east-1)
+  -format string
+        Output format: table, jsonl (default "table")
+  -results string
+        Directory of the enumeration run to read (default: the latest run in enum-results)
+
+Examples:
+  ./aws-enumerator inventory
+  ./aws-enumerator inventory -type ec2:instance -region eu-west-1
+  ./aws-enumerator inventory -tag Environment=prod -format jsonl > prod.jsonl
 `
 
 const Cloudrider_help = `
@@ -67,12 +316,20 @@ Commands:
   cred      Set up credentials (creates .env file)
   enum      Run enumeration with optional profile support
   dump      Analyze enumeration results
-  profiles  List available AWS profiles
+  profiles  List available AWS profiles with their source type and region
+  catalog   Validate the catalog of services and API calls
+  analyze-self  Evaluate the policies of the caller offline and list the granted actions
+  analyze   Analyze the stored results: privesc, exposure
+  scan-secrets  Search the stored results for credentials, keys and tokens
+  findings  Export the findings of the analyses as JSON, CSV or SARIF
+  diff      Compare the results and permissions of two runs
+  inventory List the resources found in the results by type, tag or region
 
 Use 'aws-enumerator [command] -h' for more information about a command.
 
 Profile Support:
-  You can now use AWS profiles from ~/.aws/credentials:
+  You can now use AWS profiles from ~/.aws/credentials and ~/.aws/config
+  ( static keys, assume role, credential_process, sso ):
   ./aws-enumerator enum -services all -profile myprofile
 
   To list available profiles:
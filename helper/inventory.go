@@ -0,0 +1,101 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/analyze"
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// saveInventory extracts the resources of the stored results and writes them to the inventory file of the run
+func saveInventory() {
+	resources := analyze.ExtractResources()
+	if err := analyze.SaveInventory(resources); err != nil {
+		fmt.Println(utils.Yellow("Warning:"), utils.Yellow("Unable to save the inventory:"), utils.Red(err))
+		return
+	}
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Resources:"), utils.Green(len(resources)), utils.Yellow("see"), utils.Yellow(filepath.Join(utils.FILEPATH, analyze.INVENTORY_FILE)))
+}
+
+// HandleInventoryCommand lists the resources of the inventory matching the type, tag and region filters.
+// The inventory of a run enumerated without it is extracted from the stored results first.
+func HandleInventoryCommand(types, tags, regions, format *string) {
+	resources, err := analyze.LoadInventory()
+	if os.IsNotExist(err) {
+		if len(servicemaster.StoredRegions()) == 0 {
+			fmt.Println(utils.Red("Error:"), utils.Yellow("No results found in"), utils.Red(utils.FILEPATH))
+			fmt.Println(utils.Green("Fix:"), utils.Yellow("Run `./aws-enumerator enum -services all` first"))
+			os.Exit(1)
+		}
+		resources = analyze.ExtractResources()
+		if err := analyze.SaveInventory(resources); err != nil {
+			fmt.Fprintln(os.Stderr, utils.Yellow("Warning:"), utils.Yellow("Unable to save the inventory:"), utils.Red(err))
+		}
+	} else if err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("Unable to read"), utils.Red(filepath.Join(utils.FILEPATH, analyze.INVENTORY_FILE)))
+		fmt.Println(utils.Red("Trace:"), utils.Yellow(err))
+		os.Exit(1)
+	}
+
+	filter := analyze.InventoryFilter{Types: splitList(*types), Regions: splitList(*regions), Tags: splitList(*tags)}
+	var matched []analyze.Resource
+	for _, resource := range resources {
+		if filter.Match(resource) {
+			matched = append(matched, resource)
+		}
+	}
+
+	switch *format {
+	case "jsonl":
+		encoder := json.NewEncoder(os.Stdout)
+		for _, resource := range matched {
+			encoder.Encode(resource)
+		}
+	case "table":
+		printInventory(matched)
+	default:
+		fmt.Println(utils.Red("Error:"), utils.Yellow("Unknown format "+*format+", use table or jsonl"))
+		os.Exit(1)
+	}
+}
+
+// printInventory prints a resource per line and the number of resources of every type
+func printInventory(resources []analyze.Resource) {
+	counts := make(map[string]int)
+	for _, resource := range resources {
+		counts[resource.Type]++
+		line := fmt.Sprintf("%-28s %-14s %s", resource.Type, resource.Region, resource.ID)
+		if resource.Name != "" && resource.Name != resource.ID {
+			line += " " + utils.Yellow(resource.Name)
+		}
+		if resource.Arn != "" && resource.Arn != resource.ID {
+			line += " " + resource.Arn
+		}
+		if len(resource.Tags) > 0 {
+			var pairs []string
+			for key, value := range resource.Tags {
+				pairs = append(pairs, key+"="+value)
+			}
+			sort.Strings(pairs)
+			line += " " + utils.Green("["+strings.Join(pairs, ", ")+"]")
+		}
+		fmt.Println(line)
+	}
+
+	types := make([]string, 0, len(counts))
+	for resourceType := range counts {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+	fmt.Println()
+	for _, resourceType := range types {
+		fmt.Printf("%-28s %d\n", resourceType, counts[resourceType])
+	}
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Resources:"), utils.Green(len(resources)), utils.Yellow("of"), len(types), utils.Yellow("types"))
+}
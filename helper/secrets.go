@@ -0,0 +1,35 @@
+package helper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/threatroute66/aws-enumerator/analyze"
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// HandleScanSecretsCommand scans the stored responses for secrets, the report holds redacted previews only
+func HandleScanSecretsCommand(rulesFile, services *string) {
+	ruleset, err := analyze.LoadSecretRules(*rulesFile)
+	if err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("Unable to load the secret rules"))
+		fmt.Println(utils.Red("Trace:"), utils.Yellow(err))
+		os.Exit(1)
+	}
+	if len(servicemaster.StoredRegions()) == 0 {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("No results found in"), utils.Red(utils.FILEPATH))
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Run `./aws-enumerator enum -services all` first"))
+		os.Exit(1)
+	}
+
+	report := analyze.ScanSecrets(ruleset, splitList(*services))
+	saveAnalysis(analyze.SECRETS_FILE, report)
+
+	for _, finding := range report.Findings {
+		fmt.Printf("%s %s %s/%s %s\n", utils.Red(fmt.Sprintf("%-8s", finding.Severity)), finding.ID, finding.Region, finding.Service, utils.Yellow(finding.ApiCall))
+		fmt.Printf("   %s  %s\n", finding.Evidence, finding.Description)
+	}
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Secrets found:"), utils.Green(len(report.Findings)), utils.Yellow("with"), len(report.Rules), utils.Yellow("rules, see"), utils.Yellow(filepath.Join(utils.FILEPATH, analyze.SECRETS_FILE)))
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/threatroute66/aws-enumerator/helper"
 	"github.com/threatroute66/aws-enumerator/utils"
@@ -18,9 +21,30 @@ func main() {
 	helper.Dump.Usage = func() {
 		fmt.Fprintf(os.Stderr, helper.Cloudrider_dump_help)
 	}
+	helper.Catalog.Usage = func() {
+		fmt.Fprintf(os.Stderr, helper.Cloudrider_catalog_help)
+	}
+	helper.AnalyzeSelf.Usage = func() {
+		fmt.Fprintf(os.Stderr, helper.Cloudrider_analyze_self_help)
+	}
+	helper.Analyze.Usage = func() {
+		fmt.Fprintf(os.Stderr, helper.Cloudrider_analyze_help)
+	}
+	helper.ScanSecrets.Usage = func() {
+		fmt.Fprintf(os.Stderr, helper.Cloudrider_scan_secrets_help)
+	}
+	helper.Findings.Usage = func() {
+		fmt.Fprintf(os.Stderr, helper.Cloudrider_findings_help)
+	}
+	helper.Diff.Usage = func() {
+		fmt.Fprintf(os.Stderr, helper.Cloudrider_diff_help)
+	}
+	helper.Inventory.Usage = func() {
+		fmt.Fprintf(os.Stderr, helper.Cloudrider_inventory_help)
+	}
 
 	if len(os.Args) < 2 {
-		fmt.Println(helper.Cloudrider_help)
+		fmt.Print(helper.Cloudrider_help)
 		os.Exit(1)
 	}
 
@@ -31,15 +55,82 @@ func main() {
 		fmt.Println(utils.Green("Message: "), utils.Yellow("File"), utils.Red(".env"), utils.Yellow("with AWS credentials were created in current folder"))
 	case "enum":
 		helper.Enum.Parse(os.Args[2:])
-		helper.SetEnumerationPipeline(helper.Services_enum, helper.Speed, helper.Profile)
+		// Ctrl-C / SIGTERM cancel the running api calls and the partial results are saved
+		ctx, stop := signalContext()
+		helper.SetEnumerationPipeline(ctx, helper.EnumerationOptions{
+			Services:        *helper.Services_enum,
+			Speed:           *helper.Speed,
+			Profile:         *helper.Profile,
+			Regions:         *helper.Regions_enum,
+			MaxPages:        *helper.Max_pages,
+			Concurrency:     *helper.Concurrency,
+			S3MaxKeys:       *helper.S3_max_keys,
+			RPS:             *helper.RPS,
+			AssumeRole:      *helper.Assume_role,
+			ExternalID:      *helper.External_id,
+			RoleSessionName: *helper.Role_session_name,
+			CatalogFile:     *helper.Catalog_enum,
+			Timeout:         *helper.Timeout,
+			CallTimeout:     *helper.Call_timeout,
+			Resume:          *helper.Resume,
+			Output:          *helper.Output_enum,
+		})
+		stop()
 		fmt.Println(utils.Green("Message: "), utils.Yellow("Enumeration finished"))
 	case "dump":
 		helper.Dump.Parse(os.Args[2:])
-		helper.DumpInfo(helper.Services_dump, helper.Print, helper.Filter, helper.Errors_dump)
+		helper.UseResults(*helper.Results_dump)
+		helper.DumpInfo(helper.Services_dump, helper.Regions_dump, helper.Print, helper.Filter, helper.Errors_dump)
 	case "profiles":
 		helper.HandleProfilesCommand()
+	case "catalog":
+		if len(os.Args) < 3 {
+			helper.Catalog.Usage()
+			os.Exit(1)
+		}
+		helper.Catalog.Parse(os.Args[3:])
+		helper.HandleCatalogCommand(os.Args[2], helper.Catalog_file)
+	case "analyze":
+		if len(os.Args) < 3 {
+			helper.Analyze.Usage()
+			os.Exit(1)
+		}
+		helper.Analyze.Parse(os.Args[3:])
+		helper.UseResults(*helper.Results_analyze)
+		helper.HandleAnalyzeCommand(os.Args[2], helper.Principal_analyze)
+	case "analyze-self":
+		helper.AnalyzeSelf.Parse(os.Args[2:])
+		helper.UseResults(*helper.Results_self)
+		ctx, stop := signalContext()
+		helper.HandleAnalyzeSelfCommand(ctx, helper.Profile_self, helper.Assume_role_self, helper.External_id_self, helper.Role_session_self, helper.Catalog_self, helper.All_self)
+		stop()
+	case "scan-secrets":
+		helper.ScanSecrets.Parse(os.Args[2:])
+		helper.UseResults(*helper.Results_secrets)
+		helper.HandleScanSecretsCommand(helper.Rules_secrets, helper.Services_secrets)
+	case "findings":
+		helper.Findings.Parse(os.Args[2:])
+		helper.UseResults(*helper.Results_findings)
+		helper.HandleFindingsCommand(helper.Format_findings, helper.Output_findings)
+	case "diff":
+		runs := helper.ParseWithArgs(helper.Diff, os.Args[2:])
+		helper.HandleDiffCommand(runs, helper.Services_diff, helper.Output_diff)
+	case "inventory":
+		helper.Inventory.Parse(os.Args[2:])
+		helper.UseResults(*helper.Results_inventory)
+		helper.HandleInventoryCommand(helper.Type_inventory, helper.Tag_inventory, helper.Region_inventory, helper.Format_inventory)
 	default:
-		fmt.Println(helper.Cloudrider_help)
+		fmt.Print(helper.Cloudrider_help)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
+
+// signalContext is canceled by Ctrl-C / SIGTERM, a second signal kills the process
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
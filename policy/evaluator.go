@@ -0,0 +1,225 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Decisions of the evaluation of an action
+const (
+	DECISION_ALLOWED       = "allowed"
+	DECISION_DENIED        = "denied"        // explicit deny
+	DECISION_IMPLICIT_DENY = "implicit_deny" // no statement allows the action
+	DECISION_UNKNOWN       = "unknown"       // the decision depends on conditions that can't be evaluated offline
+)
+
+// Result is the evaluation of an action against the policies of a principal
+type Result struct {
+	Action          string
+	Decision        string
+	Resources       []string `json:",omitempty"` // resources of the unconditional Allow statements
+	DeniedResources []string `json:",omitempty"` // resources of the unconditional Deny statements scoped to resources
+	Conditional     []string `json:",omitempty"` // statements with a condition matching the action
+	Statements      []string `json:",omitempty"` // statements matching the action
+	Reason          string   `json:",omitempty"`
+}
+
+// Evaluate evaluates an action against the identity policies and the permissions boundary (if any) of a principal.
+// Statements are matched on the action only: an Allow scoped to resources allows the action on those resources,
+// a Deny on every resource ("*") is an explicit deny, conditions are never evaluated and make the decision unknown.
+// Service control policies, session policies and resource policies are not taken into account.
+func Evaluate(action string, policies []Policy, boundary []Policy) Result {
+	result := evaluate_policies(action, policies)
+	if len(boundary) == 0 || (result.Decision != DECISION_ALLOWED && result.Decision != DECISION_UNKNOWN) {
+		return result
+	}
+
+	bounded := evaluate_policies(action, boundary)
+	result.Statements = append(result.Statements, bounded.Statements...)
+	result.Conditional = append(result.Conditional, bounded.Conditional...)
+	switch bounded.Decision {
+	case DECISION_DENIED:
+		result.Decision = DECISION_DENIED
+		result.Reason = "explicit deny in the permissions boundary"
+	case DECISION_IMPLICIT_DENY:
+		result.Decision = DECISION_IMPLICIT_DENY
+		result.Reason = "not allowed by the permissions boundary"
+	case DECISION_UNKNOWN:
+		result.Decision = DECISION_UNKNOWN
+		result.Reason = "conditions in the permissions boundary"
+	}
+	return result
+}
+
+// evaluate_policies applies the IAM evaluation logic to a set of policies: an explicit deny wins over any allow
+func evaluate_policies(action string, policies []Policy) Result {
+	result := Result{Action: action}
+	explicit_deny, conditional_deny, allowed, conditional_allow := false, false, false, false
+
+	for _, p := range policies {
+		if p.Document == nil {
+			continue
+		}
+		for i, statement := range p.Document.Statement {
+			if !statement.MatchesAction(action) {
+				continue
+			}
+			reference := statement_reference(p, i, statement)
+			result.Statements = append(result.Statements, reference)
+			deny := strings.EqualFold(statement.Effect, "Deny")
+
+			switch {
+			case statement.HasCondition():
+				result.Conditional = append(result.Conditional, reference)
+				if deny {
+					conditional_deny = true
+				} else {
+					conditional_allow = true
+				}
+			case deny && statement.AllResources():
+				explicit_deny = true
+			case deny:
+				result.DeniedResources = append(result.DeniedResources, statement.resources()...)
+			default:
+				allowed = true
+				result.Resources = append(result.Resources, statement.resources()...)
+			}
+		}
+	}
+
+	switch {
+	case explicit_deny:
+		result.Decision = DECISION_DENIED
+		result.Reason = "explicit deny"
+	case allowed && conditional_deny:
+		result.Decision = DECISION_UNKNOWN
+		result.Reason = "allowed unless a conditional deny applies"
+	case allowed:
+		result.Decision = DECISION_ALLOWED
+	case conditional_allow:
+		result.Decision = DECISION_UNKNOWN
+		result.Reason = "only allowed under conditions"
+	default:
+		result.Decision = DECISION_IMPLICIT_DENY
+	}
+	return result
+}
+
+// Granted reports whether the result allows the action on the resource (an ARN):
+// a resource of an Allow statement matches and no resource of a Deny statement does
+func (result Result) Granted(resource string) bool {
+	if result.Decision != DECISION_ALLOWED {
+		return false
+	}
+	return match_resources(result.Resources, resource) && !match_resources(result.DeniedResources, resource)
+}
+
+// match_resources matches an ARN against Resource patterns and "NOT " prefixed NotResource patterns
+func match_resources(patterns []string, resource string) bool {
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "NOT ") {
+			if !Match(strings.TrimPrefix(pattern, "NOT "), resource) {
+				return true
+			}
+			continue
+		}
+		if Match(pattern, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesAction reports whether the Action / NotAction of the statement covers the action
+func (statement *Statement) MatchesAction(action string) bool {
+	if len(statement.NotAction) > 0 {
+		return !match_any(statement.NotAction, action)
+	}
+	return match_any(statement.Action, action)
+}
+
+// AllResources reports whether the statement applies to every resource
+func (statement *Statement) AllResources() bool {
+	if len(statement.NotResource) > 0 {
+		return false
+	}
+	for _, resource := range statement.Resource {
+		if resource == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// resources lists the resources of the statement, NotResource entries are prefixed with "NOT "
+func (statement *Statement) resources() []string {
+	if len(statement.NotResource) == 0 {
+		return statement.Resource
+	}
+	var resources []string
+	for _, resource := range statement.NotResource {
+		resources = append(resources, "NOT "+resource)
+	}
+	return resources
+}
+
+func statement_reference(p Policy, i int, statement Statement) string {
+	reference := p.Name
+	if p.Source != "" {
+		reference = p.Source + " " + reference
+	}
+	return reference + "/" + statement_id(i, statement)
+}
+
+// statement_id is the Sid of the statement, or its position in the document
+func statement_id(i int, statement Statement) string {
+	if statement.Sid != "" {
+		return statement.Sid
+	}
+	return fmt.Sprintf("#%d", i)
+}
+
+func match_any(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		if MatchAction(pattern, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match matches a value (an ARN) against an IAM pattern, "*" matches any sequence and "?" any character.
+// Resources are case-sensitive: arn:aws:s3:::Prod/* does not cover arn:aws:s3:::prod/x
+func Match(pattern, value string) bool {
+	return match([]rune(pattern), []rune(value))
+}
+
+// MatchAction matches an action against an IAM pattern, actions are case-insensitive
+func MatchAction(pattern, action string) bool {
+	return Match(strings.ToLower(pattern), strings.ToLower(action))
+}
+
+func match(pattern, value []rune) bool {
+	// backtracking on the last "*" only, linear for IAM-sized patterns
+	p, v, star, mark := 0, 0, -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, v
+			p++
+		case star >= 0:
+			p = star + 1
+			mark++
+			v = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,252 @@
+package policy
+
+import (
+	"testing"
+)
+
+func policies(t *testing.T, documents ...string) []Policy {
+	t.Helper()
+	var parsed []Policy
+	for i, document := range documents {
+		doc, err := Parse(document)
+		if err != nil {
+			t.Fatalf("policy %d: %v", i, err)
+		}
+		parsed = append(parsed, Policy{Name: "policy", Document: doc})
+	}
+	return parsed
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		match          bool
+	}{
+		{"*", "s3:GetObject", true},
+		{"s3:*", "s3:GetObject", true},
+		{"s3:*", "s3express:CreateSession", false},
+		{"s3:Get*", "s3:GetObject", true},
+		{"s3:Get*", "s3:PutObject", false},
+		{"S3:getobject", "s3:GetObject", false},
+		{"iam:*User*", "iam:ListUserPolicies", true},
+		{"iam:*User*", "iam:ListRoles", false},
+		{"ec2:Describe?nstances", "ec2:DescribeInstances", true},
+		{"ec2:Describe?nstances", "ec2:Describenstances", false},
+		{"s3:GetObject", "s3:GetObjectAcl", false},
+		{"arn:aws:s3:::bucket/*", "arn:aws:s3:::bucket/a/b", true},
+		{"arn:aws:s3:::bucket/*", "arn:aws:s3:::bucket", false},
+		{"arn:aws:iam::*:role/*-admin", "arn:aws:iam::123456789012:role/ops-admin", true},
+		{"arn:aws:s3:::Prod/*", "arn:aws:s3:::prod/x", false},
+		{"a*b*c", "aXbYbZc", true},
+		{"a*b*c", "aXbYcZ", false},
+		{"**", "", true},
+	}
+	for _, test := range tests {
+		if got := Match(test.pattern, test.value); got != test.match {
+			t.Errorf("Match(%q, %q) = %v, want %v", test.pattern, test.value, got, test.match)
+		}
+	}
+}
+
+func TestMatchAction(t *testing.T) {
+	tests := []struct {
+		pattern, action string
+		match           bool
+	}{
+		{"S3:getobject", "s3:GetObject", true},
+		{"iam:passrole", "iam:PassRole", true},
+		{"EC2:Describe*", "ec2:describeinstances", true},
+		{"s3:Get*", "s3:putobject", false},
+	}
+	for _, test := range tests {
+		if got := MatchAction(test.pattern, test.action); got != test.match {
+			t.Errorf("MatchAction(%q, %q) = %v, want %v", test.pattern, test.action, got, test.match)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name      string
+		action    string
+		policies  []string
+		boundary  []string
+		decision  string
+		resources []string
+	}{
+		{
+			name:      "wildcard allow",
+			action:    "s3:GetObject",
+			policies:  []string{`{"Statement": {"Effect": "Allow", "Action": "s3:Get*", "Resource": "*"}}`},
+			decision:  DECISION_ALLOWED,
+			resources: []string{"*"},
+		},
+		{
+			name:     "no matching statement",
+			action:   "s3:PutObject",
+			policies: []string{`{"Statement": {"Effect": "Allow", "Action": "s3:Get*", "Resource": "*"}}`},
+			decision: DECISION_IMPLICIT_DENY,
+		},
+		{
+			name:   "deny over allow",
+			action: "iam:CreateUser",
+			policies: []string{
+				`{"Statement": {"Effect": "Allow", "Action": "*", "Resource": "*"}}`,
+				`{"Statement": {"Effect": "Deny", "Action": "iam:*", "Resource": "*"}}`,
+			},
+			decision:  DECISION_DENIED,
+			resources: []string{"*"},
+		},
+		{
+			name:      "deny scoped to resources",
+			action:    "s3:GetObject",
+			policies:  []string{`{"Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*"}, {"Effect": "Deny", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::secret/*"}]}`},
+			decision:  DECISION_ALLOWED,
+			resources: []string{"*"},
+		},
+		{
+			name:      "not action allows the others",
+			action:    "ec2:DescribeInstances",
+			policies:  []string{`{"Statement": {"Effect": "Allow", "NotAction": ["iam:*", "organizations:*"], "Resource": "*"}}`},
+			decision:  DECISION_ALLOWED,
+			resources: []string{"*"},
+		},
+		{
+			name:     "not action excludes",
+			action:   "iam:ListUsers",
+			policies: []string{`{"Statement": {"Effect": "Allow", "NotAction": ["iam:*", "organizations:*"], "Resource": "*"}}`},
+			decision: DECISION_IMPLICIT_DENY,
+		},
+		{
+			name:   "deny with not action",
+			action: "ec2:RunInstances",
+			policies: []string{
+				`{"Statement": {"Effect": "Allow", "Action": "*", "Resource": "*"}}`,
+				`{"Statement": {"Effect": "Deny", "NotAction": "ec2:Describe*", "Resource": "*"}}`,
+			},
+			decision:  DECISION_DENIED,
+			resources: []string{"*"},
+		},
+		{
+			name:   "deny with not resource is not a deny on every resource",
+			action: "s3:DeleteBucket",
+			policies: []string{
+				`{"Statement": {"Effect": "Allow", "Action": "s3:*", "Resource": "*"}}`,
+				`{"Statement": {"Effect": "Deny", "Action": "s3:DeleteBucket", "NotResource": "arn:aws:s3:::scratch"}}`,
+			},
+			decision:  DECISION_ALLOWED,
+			resources: []string{"*"},
+		},
+		{
+			name:      "allow with not resource",
+			action:    "s3:GetObject",
+			policies:  []string{`{"Statement": {"Effect": "Allow", "Action": "s3:GetObject", "NotResource": "arn:aws:s3:::secret/*"}}`},
+			decision:  DECISION_ALLOWED,
+			resources: []string{"NOT arn:aws:s3:::secret/*"},
+		},
+		{
+			name:     "conditional allow",
+			action:   "s3:GetObject",
+			policies: []string{`{"Statement": {"Effect": "Allow", "Action": "s3:GetObject", "Resource": "*", "Condition": {"Bool": {"aws:MultiFactorAuthPresent": "true"}}}}`},
+			decision: DECISION_UNKNOWN,
+		},
+		{
+			name:      "conditional deny of an allowed action",
+			action:    "s3:GetObject",
+			policies:  []string{`{"Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*"}, {"Effect": "Deny", "Action": "*", "Resource": "*", "Condition": {"IpAddress": {"aws:SourceIp": "10.0.0.0/8"}}}]}`},
+			decision:  DECISION_UNKNOWN,
+			resources: []string{"*"},
+		},
+		{
+			name:      "empty condition is no condition",
+			action:    "s3:GetObject",
+			policies:  []string{`{"Statement": {"Effect": "Allow", "Action": "s3:GetObject", "Resource": "*", "Condition": {}}}`},
+			decision:  DECISION_ALLOWED,
+			resources: []string{"*"},
+		},
+		{
+			name:      "boundary allows",
+			action:    "s3:GetObject",
+			policies:  []string{`{"Statement": {"Effect": "Allow", "Action": "*", "Resource": "*"}}`},
+			boundary:  []string{`{"Statement": {"Effect": "Allow", "Action": "s3:*", "Resource": "*"}}`},
+			decision:  DECISION_ALLOWED,
+			resources: []string{"*"},
+		},
+		{
+			name:      "boundary does not allow",
+			action:    "iam:CreateUser",
+			policies:  []string{`{"Statement": {"Effect": "Allow", "Action": "*", "Resource": "*"}}`},
+			boundary:  []string{`{"Statement": {"Effect": "Allow", "Action": "s3:*", "Resource": "*"}}`},
+			decision:  DECISION_IMPLICIT_DENY,
+			resources: []string{"*"},
+		},
+		{
+			name:      "boundary denies",
+			action:    "s3:DeleteBucket",
+			policies:  []string{`{"Statement": {"Effect": "Allow", "Action": "*", "Resource": "*"}}`},
+			boundary:  []string{`{"Statement": [{"Effect": "Allow", "Action": "*", "Resource": "*"}, {"Effect": "Deny", "Action": "s3:Delete*", "Resource": "*"}]}`},
+			decision:  DECISION_DENIED,
+			resources: []string{"*"},
+		},
+		{
+			name:      "boundary with conditions",
+			action:    "s3:GetObject",
+			policies:  []string{`{"Statement": {"Effect": "Allow", "Action": "*", "Resource": "*"}}`},
+			boundary:  []string{`{"Statement": {"Effect": "Allow", "Action": "s3:*", "Resource": "*", "Condition": {"StringEquals": {"aws:RequestedRegion": "eu-west-1"}}}}`},
+			decision:  DECISION_UNKNOWN,
+			resources: []string{"*"},
+		},
+		{
+			name:     "boundary alone grants nothing",
+			action:   "s3:GetObject",
+			boundary: []string{`{"Statement": {"Effect": "Allow", "Action": "*", "Resource": "*"}}`},
+			decision: DECISION_IMPLICIT_DENY,
+		},
+	}
+	for _, test := range tests {
+		result := Evaluate(test.action, policies(t, test.policies...), policies(t, test.boundary...))
+		if result.Decision != test.decision {
+			t.Errorf("%s: decision %s (%s), want %s", test.name, result.Decision, result.Reason, test.decision)
+		}
+		if len(result.Resources) != len(test.resources) {
+			t.Errorf("%s: resources %v, want %v", test.name, result.Resources, test.resources)
+			continue
+		}
+		for i := range test.resources {
+			if result.Resources[i] != test.resources[i] {
+				t.Errorf("%s: resources %v, want %v", test.name, result.Resources, test.resources)
+			}
+		}
+	}
+}
+
+func TestConditionsReported(t *testing.T) {
+	result := Evaluate("s3:GetObject", policies(t, `{"Statement": [{"Sid": "Mfa", "Effect": "Allow", "Action": "s3:*", "Resource": "*", "Condition": {"Bool": {"aws:MultiFactorAuthPresent": "true"}}}]}`), nil)
+	if result.Decision != DECISION_UNKNOWN || len(result.Conditional) != 1 || result.Conditional[0] != "policy/Mfa" || result.Reason == "" {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestGranted(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		resource string
+		granted  bool
+	}{
+		{"any resource", `{"Statement": {"Effect": "Allow", "Action": "s3:GetObject", "Resource": "*"}}`, "arn:aws:s3:::data/key", true},
+		{"scoped resource", `{"Statement": {"Effect": "Allow", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::data/*"}}`, "arn:aws:s3:::data/key", true},
+		{"other resource", `{"Statement": {"Effect": "Allow", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::data/*"}}`, "arn:aws:s3:::logs/key", false},
+		{"resource of another case", `{"Statement": {"Effect": "Allow", "Action": "S3:getobject", "Resource": "arn:aws:s3:::Prod/*"}}`, "arn:aws:s3:::prod/key", false},
+		{"not resource", `{"Statement": {"Effect": "Allow", "Action": "s3:GetObject", "NotResource": "arn:aws:s3:::secret/*"}}`, "arn:aws:s3:::data/key", true},
+		{"excluded by not resource", `{"Statement": {"Effect": "Allow", "Action": "s3:GetObject", "NotResource": "arn:aws:s3:::secret/*"}}`, "arn:aws:s3:::secret/key", false},
+		{"denied resource", `{"Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*"}, {"Effect": "Deny", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::secret/*"}]}`, "arn:aws:s3:::secret/key", false},
+		{"outside the denied resources", `{"Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*"}, {"Effect": "Deny", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::secret/*"}]}`, "arn:aws:s3:::data/key", true},
+		{"conditional", `{"Statement": {"Effect": "Allow", "Action": "s3:GetObject", "Resource": "*", "Condition": {"Bool": {"aws:SecureTransport": "true"}}}}`, "arn:aws:s3:::data/key", false},
+	}
+	for _, test := range tests {
+		if got := Evaluate("s3:GetObject", policies(t, test.policy), nil).Granted(test.resource); got != test.granted {
+			t.Errorf("%s: granted %v, want %v", test.name, got, test.granted)
+		}
+	}
+}
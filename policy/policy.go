@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+)
+
+// Document is an IAM policy document
+type Document struct {
+	Version   string
+	Statement Statements
+}
+
+// Statement is a statement of a policy document, Action / NotAction and Resource / NotResource are exclusive
+type Statement struct {
+	Sid         string `json:",omitempty"`
+	Effect      string
+	Principal   json.RawMessage `json:",omitempty"`
+	Action      StringList      `json:",omitempty"`
+	NotAction   StringList      `json:",omitempty"`
+	Resource    StringList      `json:",omitempty"`
+	NotResource StringList      `json:",omitempty"`
+	Condition   json.RawMessage `json:",omitempty"`
+}
+
+// Policy is a named policy document attached to a principal, Source tells where it comes from
+// ("user inline", "group dev attached", "permissions boundary" ...)
+type Policy struct {
+	Name     string
+	Source   string
+	Document *Document
+}
+
+// StringList is a JSON string or list of strings
+type StringList []string
+
+func (list *StringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*list = StringList{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*list = multiple
+	return nil
+}
+
+// Statements is a JSON statement or list of statements
+type Statements []Statement
+
+func (statements *Statements) UnmarshalJSON(data []byte) error {
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+		var single Statement
+		if err := json.Unmarshal(data, &single); err != nil {
+			return err
+		}
+		*statements = Statements{single}
+		return nil
+	}
+	var multiple []Statement
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*statements = multiple
+	return nil
+}
+
+// Unescape URL-decodes a policy document, IAM returns them as URL-encoded JSON.
+// A "+" stays a "+" (spaces are encoded as %20), documents that are not encoded are returned as they are.
+func Unescape(document string) string {
+	if decoded, err := url.PathUnescape(document); err == nil {
+		return decoded
+	}
+	return document
+}
+
+// Parse parses a policy document, URL-encoded or not
+func Parse(document string) (*Document, error) {
+	return ParseJSON([]byte(Unescape(document)))
+}
+
+// ParseJSON parses the JSON of a policy document
+func ParseJSON(data []byte) (*Document, error) {
+	document := &Document{}
+	if err := json.Unmarshal(data, document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// HasCondition reports whether the statement has a condition block
+func (statement *Statement) HasCondition() bool {
+	condition := bytes.TrimSpace(statement.Condition)
+	return len(condition) > 0 && !bytes.Equal(condition, []byte("null")) && !bytes.Equal(condition, []byte("{}"))
+}
@@ -0,0 +1,35 @@
+package policy
+
+import (
+	"testing"
+)
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		document, decoded string
+	}{
+		{"%7B%22Version%22%3A%222012-10-17%22%7D", `{"Version":"2012-10-17"}`},
+		{"arn%3Aaws%3Aiam%3A%3A123456789012%3Arole%2Fa+b", "arn:aws:iam::123456789012:role/a+b"},
+		{"a%20b", "a b"},
+		{`{"Version": "2012-10-17"}`, `{"Version": "2012-10-17"}`},
+		{"100%", "100%"},
+	}
+	for _, test := range tests {
+		if decoded := Unescape(test.document); decoded != test.decoded {
+			t.Errorf("Unescape(%q) = %q, want %q", test.document, decoded, test.decoded)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	document, err := Parse("%7B%22Statement%22%3A%7B%22Effect%22%3A%22Allow%22%2C%22Action%22%3A%22s3%3AGetObject%22%2C%22Resource%22%3A%22arn%3Aaws%3As3%3A%3A%3Aa+b%2F*%22%7D%7D")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(document.Statement) != 1 || len(document.Statement[0].Action) != 1 || document.Statement[0].Resource[0] != "arn:aws:s3:::a+b/*" {
+		t.Errorf("got %+v", document)
+	}
+	if _, err := Parse(`{"Statement": "not a statement"}`); err == nil {
+		t.Error("invalid statement parsed")
+	}
+}
@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Principals returns the principals of the statement by type ("AWS", "Service", "Federated", "CanonicalUser"),
+// "Principal": "*" is returned as {"*": ["*"]}
+func (statement *Statement) Principals() map[string][]string {
+	principals := make(map[string][]string)
+	if len(statement.Principal) == 0 {
+		return principals
+	}
+
+	var everyone string
+	if err := json.Unmarshal(statement.Principal, &everyone); err == nil {
+		principals[everyone] = []string{everyone}
+		return principals
+	}
+
+	var typed map[string]StringList
+	if err := json.Unmarshal(statement.Principal, &typed); err == nil {
+		for principal_type, values := range typed {
+			principals[principal_type] = values
+		}
+	}
+	return principals
+}
+
+// Trust is the answer of a trust (resource) policy for a principal
+type Trust struct {
+	Trusted     bool
+	Conditional bool   // only trusted under conditions
+	Explicit    bool   // the principal itself is named, no identity policy is needed in the same account
+	Statement   string // first matching statement
+}
+
+// TrustsAWSPrincipal checks whether the Allow statements of a trust policy with the action trust an IAM principal:
+// its ARN, the root of its account, the bare account id or "*". Deny statements are not evaluated.
+func TrustsAWSPrincipal(document *Document, action, principal_arn, account string) Trust {
+	return trusts(document, action, func(principal_type, value string) (bool, bool) {
+		if principal_type == "*" || (principal_type == "AWS" && value == "*") {
+			return true, false
+		}
+		if principal_type != "AWS" {
+			return false, false
+		}
+		if strings.EqualFold(value, principal_arn) {
+			return true, true
+		}
+		return value == account || value == "arn:aws:iam::"+account+":root", false
+	})
+}
+
+// TrustsEveryone checks whether the Allow statements of a resource policy trust everyone ("*" or {"AWS": "*"}) for any action
+func TrustsEveryone(document *Document) Trust {
+	return trusts(document, "", func(principal_type, value string) (bool, bool) {
+		return principal_type == "*" || (principal_type == "AWS" && value == "*"), false
+	})
+}
+
+// TrustsService checks whether the Allow statements of a trust policy with the action trust a service principal (lambda.amazonaws.com)
+func TrustsService(document *Document, action, service string) Trust {
+	return trusts(document, action, func(principal_type, value string) (bool, bool) {
+		return principal_type == "*" || (principal_type == "Service" && strings.EqualFold(value, service)), false
+	})
+}
+
+// trusts applies the matcher to the principals of the Allow statements with the action, any action when empty
+func trusts(document *Document, action string, matches func(principal_type, value string) (bool, bool)) Trust {
+	result := Trust{}
+	if document == nil {
+		return result
+	}
+	for i, statement := range document.Statement {
+		if !strings.EqualFold(statement.Effect, "Allow") || (action != "" && !statement.MatchesAction(action)) {
+			continue
+		}
+		for principal_type, values := range statement.Principals() {
+			for _, value := range values {
+				trusted, explicit := matches(principal_type, value)
+				if !trusted {
+					continue
+				}
+				// an unconditional statement wins over a conditional one
+				if result.Trusted && !result.Conditional {
+					continue
+				}
+				result = Trust{Trusted: true, Conditional: statement.HasCondition(), Explicit: explicit, Statement: statement_id(i, statement)}
+			}
+		}
+	}
+	return result
+}
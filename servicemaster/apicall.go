@@ -0,0 +1,260 @@
+package servicemaster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// APICall describes an api call of a service
+type APICall struct {
+	Name      string      // method of the SDK client
+	Input     interface{} // pointer to the input struct of the method, copied before every request
+	Paginator *Paginator  // page token fields, nil means they are detected from pagination_tokens
+	MaxPages  int         // pages fetched per invocation, 0 = the -max-pages limit
+	ReadOnly  bool        // only read-only api calls are ever invoked
+	IAMAction string      // IAM action of the call (iam:ListUsers)
+	Tags      []string
+
+	// Dependency rules, see dependencies.go
+	DependsOn string
+	Foreach   string
+	Params    map[string]string
+}
+
+// Paginator names the page token fields of an api call
+type Paginator struct {
+	InputToken  string `json:"input_token"`
+	OutputToken string `json:"output_token"`
+}
+
+// Dependent reports whether the inputs of the api call are derived from another api call
+func (apicall *APICall) Dependent() bool {
+	return apicall.DependsOn != ""
+}
+
+// Name prefixes of the read-only api calls
+var read_only_prefixes = []string{
+	"Batch", "Describe", "Get", "Head", "List", "Lookup", "Search",
+}
+
+// IsReadOnly reports whether the api call name has a read-only prefix
+func IsReadOnly(apicall_name string) bool {
+	for _, prefix := range read_only_prefixes {
+		if strings.HasPrefix(apicall_name, prefix) {
+			// BatchGetX / BatchDescribeX are reads, BatchDeleteX is not
+			if prefix == "Batch" {
+				return IsReadOnly(strings.TrimPrefix(apicall_name, prefix))
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// APICallError is a problem of an api call found by ValidateAPICalls
+type APICallError struct {
+	Service string
+	ApiCall string
+	Message string
+}
+
+func (call_err *APICallError) Error() string {
+	return call_err.Service + ":" + call_err.ApiCall + ": " + call_err.Message
+}
+
+var context_type = reflect.TypeOf((*context.Context)(nil)).Elem()
+var error_type = reflect.TypeOf((*error)(nil)).Elem()
+
+// ValidateAPICalls checks the api calls of a service against its SDK client without sending any request:
+// method names and signatures, input types, read-only flags, paginators and dependency rules
+func ValidateAPICalls(svc_name string, client interface{}, apicalls []APICall) []*APICallError {
+	var problems []*APICallError
+	report := func(apicall_name, format string, args ...interface{}) {
+		problems = append(problems, &APICallError{Service: svc_name, ApiCall: apicall_name, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// output types of the api calls with a valid signature and input
+	outputs := make(map[string]reflect.Type)
+	seen := make(map[string]bool)
+	for _, apicall := range apicalls {
+		if seen[apicall.Name] {
+			report(apicall.Name, "declared twice")
+			continue
+		}
+		seen[apicall.Name] = true
+
+		method, ok := reflect.TypeOf(client).MethodByName(apicall.Name)
+		if !ok {
+			report(apicall.Name, "unknown api call of %T", client)
+			continue
+		}
+
+		// func (client, context.Context, *Input, ...func(*Options)) (*Output, error)
+		signature := method.Type
+		if signature.NumIn() < 3 || signature.In(1) != context_type || signature.In(2).Kind() != reflect.Ptr ||
+			signature.NumOut() != 2 || signature.Out(1) != error_type || signature.Out(0).Kind() != reflect.Ptr {
+			report(apicall.Name, "%s is not an api call", signature)
+			continue
+		}
+		if input_type := reflect.TypeOf(apicall.Input); input_type != signature.In(2) {
+			report(apicall.Name, "input type %v does not match %v", input_type, signature.In(2))
+			continue
+		}
+		outputs[apicall.Name] = signature.Out(0)
+
+		if !apicall.ReadOnly {
+			report(apicall.Name, "not a read-only api call")
+		}
+		if apicall.Paginator != nil {
+			if _, ok := signature.In(2).Elem().FieldByName(apicall.Paginator.InputToken); !ok {
+				report(apicall.Name, "paginator input token %s not found in %v", apicall.Paginator.InputToken, signature.In(2))
+			}
+			if _, ok := signature.Out(0).Elem().FieldByName(apicall.Paginator.OutputToken); !ok {
+				report(apicall.Name, "paginator output token %s not found in %v", apicall.Paginator.OutputToken, signature.Out(0))
+			}
+		}
+	}
+
+	// the stored response of a dependent api call is a list of records (Input / Output per invocation)
+	stored := make(map[string]reflect.Type)
+	for _, apicall := range apicalls {
+		if output, ok := outputs[apicall.Name]; ok {
+			stored[apicall.Name] = output
+			if apicall.Dependent() {
+				stored[apicall.Name] = record_type(reflect.TypeOf(apicall.Input), output)
+			}
+		}
+	}
+
+	for _, apicall := range apicalls {
+		if !apicall.Dependent() || outputs[apicall.Name] == nil {
+			continue
+		}
+		source, ok := stored[apicall.DependsOn]
+		if !ok {
+			report(apicall.Name, "depends on %s, which is not a valid api call of the service", apicall.DependsOn)
+			continue
+		}
+		if err := validate_dependency(source, reflect.TypeOf(apicall.Input).Elem(), apicall); err != nil {
+			report(apicall.Name, "%v", err)
+		}
+	}
+	return problems
+}
+
+// record_type is the type of the stored response of a dependent api call with typed Input and Output
+func record_type(input, output reflect.Type) reflect.Type {
+	return reflect.SliceOf(reflect.StructOf([]reflect.StructField{
+		{Name: "Input", Type: input},
+		{Name: "Output", Type: output},
+	}))
+}
+
+// validate_dependency resolves the foreach path and the params on the types of the dependency response and the input
+func validate_dependency(source, input reflect.Type, apicall APICall) error {
+	chain, err := item_types(source, []reflect.Type{source}, true, split_path(apicall.Foreach))
+	if err != nil {
+		return fmt.Errorf("foreach %q: %v", apicall.Foreach, err)
+	}
+	if chain == nil {
+		// an interface on the way, can only be resolved at runtime
+		return nil
+	}
+
+	for field_name, path := range apicall.Params {
+		field, ok := input.FieldByName(field_name)
+		if !ok {
+			return fmt.Errorf("param %s: no such field in %v", field_name, input)
+		}
+		value, err := param_type(chain, path)
+		if err != nil {
+			return fmt.Errorf("param %s %q: %v", field_name, path, err)
+		}
+		if value != nil && !settable(field.Type, value) {
+			return fmt.Errorf("param %s: %v can't be assigned to %v", field_name, value, field.Type)
+		}
+	}
+	return nil
+}
+
+// item_types is the static version of collect_items: the types of the enclosing items and the item itself
+func item_types(t reflect.Type, chain []reflect.Type, at_anchor bool, path []string) ([]reflect.Type, error) {
+	t = indirect_type(t)
+	if t.Kind() == reflect.Interface {
+		return nil, nil
+	}
+
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return item_types(t.Elem(), append(chain, t.Elem()), true, path)
+	}
+
+	if len(path) == 0 {
+		if at_anchor {
+			return chain, nil
+		}
+		return append(chain, t), nil
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v has no field %s", t, path[0])
+	}
+	field, ok := t.FieldByName(path[0])
+	if !ok {
+		return nil, fmt.Errorf("%v has no field %s", t, path[0])
+	}
+	return item_types(field.Type, chain, false, path[1:])
+}
+
+// param_type is the static version of resolve_param, nil means the type is only known at runtime
+func param_type(chain []reflect.Type, path string) (reflect.Type, error) {
+	item := len(chain) - 1
+	for strings.HasPrefix(path, "../") {
+		path = strings.TrimPrefix(path, "../")
+		if item == 0 {
+			return nil, fmt.Errorf("no enclosing item")
+		}
+		item--
+	}
+
+	t := indirect_type(chain[item])
+	for _, name := range split_path(path) {
+		if t.Kind() == reflect.Interface {
+			return nil, nil
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("%v has no field %s", t, name)
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			return nil, fmt.Errorf("%v has no field %s", t, name)
+		}
+		t = indirect_type(field.Type)
+	}
+	if t.Kind() == reflect.Interface {
+		return nil, nil
+	}
+	return t, nil
+}
+
+// settable is the static version of set_field
+func settable(field, value reflect.Type) bool {
+	switch {
+	case value.AssignableTo(field):
+		return true
+	case field.Kind() == reflect.Ptr && convertible(value, field.Elem()):
+		return true
+	case field.Kind() == reflect.Slice && convertible(value, field.Elem()):
+		return true
+	default:
+		return convertible(value, field)
+	}
+}
+
+func indirect_type(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
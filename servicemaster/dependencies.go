@@ -0,0 +1,231 @@
+package servicemaster
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Dependent api calls are declared with additional fields of APICall:
+//
+//	DependsOn: api call of the same service whose response drives the inputs
+//	Foreach:   path of the items in the dependency response, slices are flattened ("Functions", "Output.PolicyNames")
+//	Params:    input field -> path relative to the item ("." is the item itself,
+//	           every leading "../" moves to the enclosing item one slice level up)
+//
+// A copy of Input is filled for every item and the api call is invoked once per input.
+
+// dependency_record is the stored result of a single invocation of a dependent api call
+type dependency_record struct {
+	Input  interface{}
+	Output interface{} `json:",omitempty"`
+	Error  *CallError  `json:",omitempty"`
+}
+
+// dependency_item is a value found by the foreach path with the items of the enclosing slice levels
+type dependency_item struct {
+	value  reflect.Value
+	parent *dependency_item
+}
+
+// call_stages groups the api calls by dependency depth, independent calls are in the first stage
+func (svc *ServiceMaster) call_stages() [][]int {
+	depth := make(map[int]int)
+
+	var call_depth func(it int, seen int) int
+	call_depth = func(it int, seen int) int {
+		if d, ok := depth[it]; ok {
+			return d
+		}
+		d := 0
+		if svc.ApiCalls[it].Dependent() && seen <= len(svc.ApiCalls) {
+			if source := svc.call_index(svc.ApiCalls[it].DependsOn); source >= 0 {
+				d = call_depth(source, seen+1) + 1
+			} else {
+				d = 1
+			}
+		}
+		depth[it] = d
+		return d
+	}
+
+	var stages [][]int
+	for it := range svc.ApiCalls {
+		d := call_depth(it, 0)
+		for len(stages) <= d {
+			stages = append(stages, nil)
+		}
+		stages[d] = append(stages[d], it)
+	}
+	return stages
+}
+
+// call_index returns the position of an api call in ApiCalls, -1 if it is not declared
+func (svc *ServiceMaster) call_index(apicall_name string) int {
+	for it := range svc.ApiCalls {
+		if svc.ApiCalls[it].Name == apicall_name {
+			return it
+		}
+	}
+	return -1
+}
+
+// dependent_call invokes the api call for every input derived from the response of its dependency
+func (svc *ServiceMaster) dependent_call(it int) (interface{}, error) {
+	apicall := &svc.ApiCalls[it]
+	dependency := apicall.DependsOn
+
+	source, ok := svc.stored_response(dependency)
+	if !ok {
+		return nil, &CallError{Class: ERROR_SKIPPED, Message: "dependency " + dependency + " returned no result"}
+	}
+
+	inputs := dependent_inputs(source, apicall)
+	records := []dependency_record{}
+	failed := 0
+	var last_err *CallError
+	for _, input := range inputs {
+		response, err := svc.paginate(apicall, input)
+		if err != nil {
+			failed++
+			last_err = classify_error(err)
+			records = append(records, dependency_record{Input: input, Error: last_err})
+			continue
+		}
+		records = append(records, dependency_record{Input: input, Output: response})
+	}
+
+	// the call failed only if every input failed, the class of the last error stands for all of them
+	if len(inputs) > 0 && failed == len(inputs) {
+		all_failed := *last_err
+		all_failed.Message = fmt.Sprintf("%d of %d inputs failed, last error: %s", failed, len(inputs), last_err.Message)
+		return nil, &all_failed
+	}
+	return records, nil
+}
+
+// dependent_inputs builds a copy of the input template for every item of the dependency response
+func dependent_inputs(source interface{}, apicall *APICall) []interface{} {
+	root := &dependency_item{value: reflect.ValueOf(source)}
+	items := collect_items(root.value, root, true, split_path(apicall.Foreach))
+
+	var inputs []interface{}
+	for _, item := range items {
+		input := copy_input(apicall.Input)
+		complete := true
+		for field_name, path := range apicall.Params {
+			value, found := resolve_param(item, path)
+			if !found || !set_field(input.Elem().FieldByName(field_name), value) {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			inputs = append(inputs, input.Interface())
+		}
+	}
+	return inputs
+}
+
+// collect_items walks the path and flattens every slice found on the way,
+// anchor is the enclosing item (slice element or response root) of the current value
+func collect_items(value reflect.Value, anchor *dependency_item, at_anchor bool, path []string) []*dependency_item {
+	value = indirect(value)
+	if !value.IsValid() {
+		return nil
+	}
+
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		var items []*dependency_item
+		for i := 0; i < value.Len(); i++ {
+			element := &dependency_item{value: value.Index(i), parent: anchor}
+			items = append(items, collect_items(element.value, element, true, path)...)
+		}
+		return items
+	}
+
+	if len(path) == 0 {
+		if at_anchor {
+			return []*dependency_item{anchor}
+		}
+		return []*dependency_item{{value: value, parent: anchor}}
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	field := value.FieldByName(path[0])
+	if !field.IsValid() {
+		return nil
+	}
+	return collect_items(field, anchor, false, path[1:])
+}
+
+// resolve_param finds the value of a param path relative to the item
+func resolve_param(item *dependency_item, path string) (reflect.Value, bool) {
+	for strings.HasPrefix(path, "../") {
+		path = strings.TrimPrefix(path, "../")
+		if item.parent == nil {
+			return reflect.Value{}, false
+		}
+		item = item.parent
+	}
+
+	value := indirect(item.value)
+	for _, name := range split_path(path) {
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		value = indirect(value.FieldByName(name))
+	}
+	if !value.IsValid() || value.IsZero() {
+		return reflect.Value{}, false
+	}
+	return value, true
+}
+
+// set_field assigns the value to the input field, converting between T, *T, []T and string enums
+func set_field(field, value reflect.Value) bool {
+	if !field.IsValid() || !field.CanSet() {
+		return false
+	}
+
+	switch {
+	case value.Type().AssignableTo(field.Type()):
+		field.Set(value)
+	case field.Kind() == reflect.Ptr && convertible(value.Type(), field.Type().Elem()):
+		ptr := reflect.New(field.Type().Elem())
+		ptr.Elem().Set(value.Convert(field.Type().Elem()))
+		field.Set(ptr)
+	case field.Kind() == reflect.Slice && convertible(value.Type(), field.Type().Elem()):
+		field.Set(reflect.Append(reflect.MakeSlice(field.Type(), 0, 1), value.Convert(field.Type().Elem())))
+	case convertible(value.Type(), field.Type()):
+		field.Set(value.Convert(field.Type()))
+	default:
+		return false
+	}
+	return true
+}
+
+// convertible only allows conversions between types of the same kind (no int -> string surprises)
+func convertible(from, to reflect.Type) bool {
+	return from.Kind() == to.Kind() && from.ConvertibleTo(to)
+}
+
+// indirect dereferences pointers and interfaces
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+func split_path(path string) []string {
+	if path == "" || path == "." {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
@@ -0,0 +1,128 @@
+package servicemaster
+
+import (
+	"reflect"
+	"testing"
+)
+
+type service_status string
+
+type test_cluster struct {
+	ClusterName *string
+	Status      string
+	Services    []string
+}
+
+type test_clusters_output struct {
+	Clusters []test_cluster
+	Owner    *string
+}
+
+type test_service_input struct {
+	Cluster  *string
+	Service  *string
+	Services []string
+	Owner    *string
+	Status   service_status
+}
+
+func TestDependentInputs(t *testing.T) {
+	source := &test_clusters_output{
+		Owner: text("ops"),
+		Clusters: []test_cluster{
+			{ClusterName: text("web"), Status: "ACTIVE", Services: []string{"front", "api"}},
+			{ClusterName: text("batch"), Services: []string{"jobs"}},
+			{Services: []string{"orphan"}},
+		},
+	}
+	tests := []struct {
+		name    string
+		foreach string
+		params  map[string]string
+		inputs  []test_service_input
+	}{
+		{
+			name:    "field of every item",
+			foreach: "Clusters",
+			params:  map[string]string{"Cluster": "ClusterName"},
+			inputs:  []test_service_input{{Cluster: text("web")}, {Cluster: text("batch")}},
+		},
+		{
+			name:    "nested slices are flattened",
+			foreach: "Clusters.Services",
+			params:  map[string]string{"Service": ".", "Cluster": "../ClusterName"},
+			inputs: []test_service_input{
+				{Cluster: text("web"), Service: text("front")},
+				{Cluster: text("web"), Service: text("api")},
+				{Cluster: text("batch"), Service: text("jobs")},
+			},
+		},
+		{
+			name:    "parent of the parent is the response",
+			foreach: "Clusters.Services",
+			params:  map[string]string{"Service": ".", "Owner": "../../Owner"},
+			inputs: []test_service_input{
+				{Service: text("front"), Owner: text("ops")},
+				{Service: text("api"), Owner: text("ops")},
+				{Service: text("jobs"), Owner: text("ops")},
+				{Service: text("orphan"), Owner: text("ops")},
+			},
+		},
+		{
+			name:    "above the response",
+			foreach: "Clusters",
+			params:  map[string]string{"Owner": "../../Owner"},
+		},
+		{
+			name:    "value into a list",
+			foreach: "Clusters.Services",
+			params:  map[string]string{"Services": "."},
+			inputs:  []test_service_input{{Services: []string{"front"}}, {Services: []string{"api"}}, {Services: []string{"jobs"}}, {Services: []string{"orphan"}}},
+		},
+		{
+			name:    "string enum",
+			foreach: "Clusters",
+			params:  map[string]string{"Status": "Status"},
+			inputs:  []test_service_input{{Status: "ACTIVE"}},
+		},
+		{
+			name:    "unknown field",
+			foreach: "Clusters",
+			params:  map[string]string{"Cluster": "Name"},
+		},
+		{
+			name:    "unknown foreach",
+			foreach: "Instances",
+			params:  map[string]string{"Cluster": "ClusterName"},
+		},
+	}
+	template := &test_service_input{}
+	for _, test := range tests {
+		apicall := &APICall{Name: "DescribeServices", Input: template, DependsOn: "ListClusters", Foreach: test.foreach, Params: test.params}
+		var inputs []test_service_input
+		for _, input := range dependent_inputs(source, apicall) {
+			inputs = append(inputs, *input.(*test_service_input))
+		}
+		if !reflect.DeepEqual(inputs, test.inputs) {
+			t.Errorf("%s: got %d inputs %+v, want %+v", test.name, len(inputs), inputs, test.inputs)
+		}
+	}
+	if !reflect.DeepEqual(template, &test_service_input{}) {
+		t.Errorf("the input template was modified: %+v", template)
+	}
+}
+
+func TestCallStages(t *testing.T) {
+	svc := &ServiceMaster{ApiCalls: []APICall{
+		{Name: "GetBucketPolicy", DependsOn: "ListBuckets"},
+		{Name: "ListBuckets"},
+		{Name: "GetObjectAcl", DependsOn: "ListObjectsV2"},
+		{Name: "ListObjectsV2", DependsOn: "ListBuckets"},
+		{Name: "GetMissing", DependsOn: "ListUnknown"},
+	}}
+	stages := svc.call_stages()
+	want := [][]int{{1}, {0, 3, 4}, {2}}
+	if !reflect.DeepEqual(stages, want) {
+		t.Errorf("stages %v, want %v", stages, want)
+	}
+}
@@ -0,0 +1,246 @@
+package servicemaster
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+)
+
+// Classes of the errors stored in <svc>_errors.json
+const (
+	ERROR_ACCESS_DENIED        = "access_denied"
+	ERROR_NOT_SUBSCRIBED       = "not_subscribed"
+	ERROR_INVALID_INPUT        = "invalid_input"
+	ERROR_INVALID_CREDENTIALS  = "invalid_credentials"
+	ERROR_THROTTLED            = "throttled"
+	ERROR_ENDPOINT_UNAVAILABLE = "endpoint_unavailable"
+	ERROR_TRANSIENT            = "transient"
+	ERROR_SKIPPED              = "skipped"
+	ERROR_TIMEOUT              = "timeout"  // the request exceeded -call-timeout
+	ERROR_CANCELED             = "canceled" // the enumeration was interrupted or exceeded -timeout
+	ERROR_UNKNOWN              = "unknown"
+)
+
+// Retries of throttled and transient errors, the only retries: the SDK clients send every request once (utils.NewAWSConfig)
+const (
+	MAX_ATTEMPTS  = 5
+	BACKOFF_BASE  = 500 * time.Millisecond
+	BACKOFF_LIMIT = 20 * time.Second
+)
+
+// CallError is the classified error of an api call
+type CallError struct {
+	Class      string
+	Code       string `json:",omitempty"`
+	Message    string
+	RequestID  string `json:",omitempty"`
+	StatusCode int    `json:",omitempty"`
+	Attempts   int    `json:",omitempty"`
+}
+
+func (call_err *CallError) Error() string {
+	if call_err.Code != "" {
+		return call_err.Code + ": " + call_err.Message
+	}
+	return call_err.Message
+}
+
+// AWS error codes of every class, everything else is classified by HTTP status code
+var error_codes = map[string][]string{
+	ERROR_ACCESS_DENIED: {
+		"AccessDenied", "AccessDeniedException", "AuthorizationError", "AuthorizationErrorException",
+		"Forbidden", "ForbiddenException", "InsufficientPrivilegesException", "NotAuthorized",
+		"NotAuthorizedException", "UnauthorizedAccess", "UnauthorizedException", "UnauthorizedOperation",
+	},
+	ERROR_NOT_SUBSCRIBED: {
+		"AWSOrganizationsNotInUseException", "NotSubscribedException", "OptInRequired",
+		"SubscriptionRequiredException", "SubscriptionRequired",
+	},
+	ERROR_INVALID_INPUT: {
+		"BadRequestException", "InvalidAction", "InvalidArgumentException", "InvalidInput",
+		"InvalidInputException", "InvalidParameter", "InvalidParameterCombination", "InvalidParameterException",
+		"InvalidParameterValue", "InvalidParameterValueException", "InvalidRequestException", "MissingParameter",
+		"MissingRequiredParameter", "SerializationException", "UnknownOperationException", "UnsupportedOperation",
+		"ValidationError", "ValidationException",
+	},
+	ERROR_INVALID_CREDENTIALS: {
+		"AuthFailure", "ExpiredToken", "ExpiredTokenException", "InvalidClientTokenId",
+		"SignatureDoesNotMatch", "UnrecognizedClientException",
+	},
+	ERROR_THROTTLED: {
+		"BandwidthLimitExceeded", "EC2ThrottledException", "PriorRequestNotComplete",
+		"ProvisionedThroughputExceededException", "RequestLimitExceeded", "RequestThrottled",
+		"RequestThrottledException", "SlowDown", "ThrottledException", "Throttling", "ThrottlingException",
+		"TooManyRequestsException", "TransactionInProgressException",
+	},
+	ERROR_TRANSIENT: {
+		"InternalError", "InternalFailure", "InternalServerError", "InternalServiceError", "RequestTimeout",
+		"RequestTimeoutException", "ServiceUnavailable", "ServiceUnavailableException",
+	},
+}
+
+// classify_error converts any error of an api call into a CallError
+func classify_error(err error) *CallError {
+	var call_err *CallError
+	if errors.As(err, &call_err) {
+		return call_err
+	}
+
+	call_err = &CallError{Class: ERROR_UNKNOWN, Message: err.Error()}
+
+	var api_err smithy.APIError
+	if errors.As(err, &api_err) {
+		call_err.Code = api_err.ErrorCode()
+		call_err.Message = api_err.ErrorMessage()
+	}
+
+	var response_err *awshttp.ResponseError
+	if errors.As(err, &response_err) {
+		call_err.RequestID = response_err.ServiceRequestID()
+		call_err.StatusCode = response_err.HTTPStatusCode()
+	}
+
+	if call_err.Code != "" {
+		for class, codes := range error_codes {
+			for _, code := range codes {
+				if call_err.Code == code {
+					call_err.Class = class
+					return call_err
+				}
+			}
+		}
+	}
+
+	var invalid_params smithy.InvalidParamsError
+	var dns_err *net.DNSError
+	var net_err net.Error
+	message := strings.ToLower(err.Error())
+	switch {
+	case errors.As(err, &invalid_params):
+		call_err.Class = ERROR_INVALID_INPUT
+	case errors.As(err, &dns_err), strings.Contains(message, "no such host"), strings.Contains(message, "resolve endpoint"):
+		call_err.Class = ERROR_ENDPOINT_UNAVAILABLE
+	case strings.Contains(message, "retry quota exceeded"):
+		call_err.Class = ERROR_THROTTLED
+	case call_err.StatusCode == 429:
+		call_err.Class = ERROR_THROTTLED
+	case call_err.StatusCode == 401 || call_err.StatusCode == 403:
+		call_err.Class = ERROR_ACCESS_DENIED
+	case call_err.StatusCode == 400 || call_err.StatusCode == 404:
+		call_err.Class = ERROR_INVALID_INPUT
+	case call_err.StatusCode >= 500:
+		call_err.Class = ERROR_TRANSIENT
+	case errors.As(err, &net_err), errors.Is(err, context.DeadlineExceeded), strings.Contains(message, "connection reset"):
+		call_err.Class = ERROR_TRANSIENT
+	}
+	return call_err
+}
+
+// retryable reports whether the request may succeed when it is sent again
+func retryable(call_err *CallError) bool {
+	return call_err.Class == ERROR_THROTTLED || call_err.Class == ERROR_TRANSIENT
+}
+
+// backoff returns the full jitter exponential delay of a retry
+func backoff(attempt int) time.Duration {
+	limit := BACKOFF_BASE << uint(attempt-1)
+	if limit <= 0 || limit > BACKOFF_LIMIT {
+		limit = BACKOFF_LIMIT
+	}
+	return time.Duration(rand.Int63n(int64(limit)) + 1)
+}
+
+// invoke sends a single request of the api call, retrying throttling and transient errors.
+// Every request runs with the context of the enumeration, bounded by the call timeout.
+func (svc *ServiceMaster) invoke(method, input reflect.Value) (reflect.Value, *CallError) {
+	var response reflect.Value
+	call_err := svc.request(svc.context(), func(ctx context.Context) error {
+		s := method.Call(
+			[]reflect.Value{
+				reflect.ValueOf(ctx),
+				input,
+			},
+		)
+		response = s[0]
+		err, _ := s[1].Interface().(error)
+		return err
+	})
+	if call_err != nil {
+		return reflect.Value{}, call_err
+	}
+	return response, nil
+}
+
+// request paces, counts and retries the requests of send. The context handed to send carries the service,
+// the requests an SDK client wrapper sends on its own go through Request with it.
+func (svc *ServiceMaster) request(ctx context.Context, send func(context.Context) error) *CallError {
+	for attempt := 1; ; attempt++ {
+		if err := svc.throttle(ctx); err != nil {
+			return canceled(ctx)
+		}
+
+		atomic.AddInt64(&requests_sent, 1)
+		call_ctx, cancel := svc.call_context(ctx)
+		err := send(context.WithValue(call_ctx, service_key{}, svc))
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return canceled(ctx)
+		}
+		call_err := classify_error(err)
+		if svc.CallTimeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+			call_err.Class = ERROR_TIMEOUT
+		}
+		call_err.Attempts = attempt
+		if !retryable(call_err) || attempt >= MAX_ATTEMPTS {
+			return call_err
+		}
+
+		select {
+		case <-time.After(backoff(attempt)):
+		case <-ctx.Done():
+			return canceled(ctx)
+		}
+	}
+}
+
+// service_key holds the service sending a request in its context
+type service_key struct{}
+
+// Request sends a request that an SDK client wrapper makes on its own, outside of the api calls of the catalog
+// ( the GetBucketLocation lookup of the s3 client ), with the rate limiters, the request counter and the retries
+// of the service the wrapper was invoked by. Without a service in ctx the request is sent as is.
+func Request(ctx context.Context, send func(context.Context) error) error {
+	svc, ok := ctx.Value(service_key{}).(*ServiceMaster)
+	if !ok {
+		return send(ctx)
+	}
+	if call_err := svc.request(ctx, send); call_err != nil {
+		return call_err
+	}
+	return nil
+}
+
+// call_context bounds a single request by the call timeout
+func (svc *ServiceMaster) call_context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if svc.CallTimeout > 0 {
+		return context.WithTimeout(ctx, svc.CallTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// canceled is the error of the requests of an interrupted enumeration
+func canceled(ctx context.Context) *CallError {
+	return &CallError{Class: ERROR_CANCELED, Message: "enumeration stopped: " + ctx.Err().Error()}
+}
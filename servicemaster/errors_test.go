@@ -0,0 +1,56 @@
+package servicemaster
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// nested_client sends a request of its own through Request before answering, like the bucket lookup of the s3 client
+type nested_client struct {
+	nested int
+}
+
+func (client *nested_client) ListItems(ctx context.Context, input *page_input) (*page_output, error) {
+	err := Request(ctx, func(ctx context.Context) error {
+		client.nested++
+		if client.nested == 1 {
+			return &CallError{Class: ERROR_THROTTLED, Message: "slow down"}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &page_output{Items: []string{"a"}}, nil
+}
+
+func TestRequestOfClientWrapper(t *testing.T) {
+	client := &nested_client{}
+	svc := &ServiceMaster{Svc: client}
+	before := atomic.LoadInt64(&requests_sent)
+	response, err := svc.invoke(reflect.ValueOf(client).MethodByName("ListItems"), reflect.ValueOf(&page_input{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items := response.Interface().(*page_output).Items; len(items) != 1 {
+		t.Errorf("got %v", items)
+	}
+	// the api call and the two attempts of the nested request
+	if sent := atomic.LoadInt64(&requests_sent) - before; sent != 3 || client.nested != 2 {
+		t.Errorf("%d requests counted, %d nested requests sent", sent, client.nested)
+	}
+}
+
+func TestRequestWithoutService(t *testing.T) {
+	before := atomic.LoadInt64(&requests_sent)
+	sent := 0
+	err := Request(context.Background(), func(ctx context.Context) error {
+		sent++
+		return nil
+	})
+	if err != nil || sent != 1 || atomic.LoadInt64(&requests_sent) != before {
+		t.Errorf("sent %d, counted %d: %v", sent, atomic.LoadInt64(&requests_sent)-before, err)
+	}
+}
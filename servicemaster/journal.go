@@ -0,0 +1,172 @@
+package servicemaster
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// JOURNAL_FILE is the checkpoint journal of the enumeration, written to utils.FILEPATH
+const JOURNAL_FILE = "journal.jsonl"
+
+// journal_entry is a line of the journal: a page fetched by an api call. Input identifies the invocation,
+// a dependent api call is invoked once per input derived from its dependency.
+type journal_entry struct {
+	Service  string
+	Region   string
+	ApiCall  string
+	Input    string
+	Page     int
+	Last     bool // no page follows
+	Response json.RawMessage
+}
+
+// journal appends every fetched page to JOURNAL_FILE. On -resume the pages of the previous run are replayed
+// instead of being requested again, the enumeration continues with the first missing page.
+// The pages are the raw responses ( secret values, SSM parameters, Lambda environments ... ), they can't be
+// redacted without breaking the replay, so the file is only readable by its owner.
+type journal struct {
+	file     *os.File
+	mutex    sync.Mutex
+	pages    map[string][]journal_entry // pages of the previous run per invocation
+	replayed int64
+	failed   bool
+}
+
+// The journal of the running enumeration, created by ServiceCall
+var run_journal *journal
+
+// open_journal starts a new journal, or loads the journal of the previous run and appends to it when resuming
+func open_journal(resume bool) (*journal, error) {
+	if err := os.MkdirAll(utils.FILEPATH, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(utils.FILEPATH, JOURNAL_FILE)
+
+	j := &journal{pages: make(map[string][]journal_entry)}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		if err := j.load(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	file, err := os.OpenFile(path, flags, 0600)
+	if err != nil {
+		return nil, err
+	}
+	// the journal of a previous version was created readable by everyone
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		return nil, err
+	}
+	j.file = file
+	return j, nil
+}
+
+// load indexes the pages of the journal, a line cut by an interruption ends the journal
+func (j *journal) load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 256*1024*1024)
+	for scanner.Scan() {
+		var entry journal_entry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			break
+		}
+		key := journal_key(entry.Service, entry.Region, entry.ApiCall, entry.Input)
+		// pages are only replayed in order, a gap restarts the invocation from the page of the gap
+		if len(j.pages[key]) == entry.Page {
+			j.pages[key] = append(j.pages[key], entry)
+		}
+	}
+	return scanner.Err()
+}
+
+// page_count returns the number of pages loaded from the previous run
+func (j *journal) page_count() int {
+	total := 0
+	for _, pages := range j.pages {
+		total += len(pages)
+	}
+	return total
+}
+
+// replay returns the typed responses of the journaled pages of an invocation, output is the response type
+// of the api call. An undecodable page drops it and the following ones, they are requested again.
+func (j *journal) replay(key string, output reflect.Type) ([]reflect.Value, bool) {
+	if j == nil {
+		return nil, false
+	}
+	j.mutex.Lock()
+	entries := j.pages[key]
+	j.mutex.Unlock()
+
+	var pages []reflect.Value
+	for _, entry := range entries {
+		page := reflect.New(output.Elem())
+		if json.Unmarshal(entry.Response, page.Interface()) != nil {
+			return pages, false
+		}
+		pages = append(pages, page)
+		atomic.AddInt64(&j.replayed, 1)
+		if entry.Last {
+			return pages, true
+		}
+	}
+	return pages, false
+}
+
+// record appends a fetched page, a failing journal only prints a warning, the enumeration goes on
+func (j *journal) record(entry journal_entry, response interface{}) {
+	if j == nil {
+		return
+	}
+	data, err := json.Marshal(response)
+	if err == nil {
+		entry.Response = data
+		data, err = json.Marshal(entry)
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if err == nil {
+		_, err = j.file.Write(append(data, '\n'))
+	}
+	if err != nil && !j.failed {
+		j.failed = true
+		fmt.Println(utils.Yellow("Warning:"), utils.Yellow("Unable to write the journal, the run can't be fully resumed:"), utils.Red(err))
+	}
+}
+
+func (j *journal) close() {
+	if j != nil {
+		j.file.Close()
+	}
+}
+
+// journal_key identifies an invocation: service, region, api call and the hash of its first input
+func journal_key(service, region, apicall, input string) string {
+	return service + "|" + region + "|" + apicall + "|" + input
+}
+
+func input_hash(input interface{}) string {
+	data, _ := json.Marshal(input)
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,168 @@
+package servicemaster
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// use_run_directory points utils.FILEPATH at a temporary run directory for the test
+func use_run_directory(t *testing.T) string {
+	dir := t.TempDir()
+	current := utils.FILEPATH
+	utils.SetOutputDir(dir)
+	t.Cleanup(func() { utils.SetOutputDir(current) })
+	return dir
+}
+
+func record_pages(t *testing.T, resume bool, entries ...journal_entry) {
+	j, err := open_journal(resume)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		j.record(entry, &page_output{Items: []string{entry.ApiCall}, NextToken: text("t")})
+	}
+	j.close()
+}
+
+func TestJournalReplay(t *testing.T) {
+	use_run_directory(t)
+	first := journal_entry{Service: "iam", Region: "global", ApiCall: "ListUsers", Input: input_hash(&page_input{})}
+	other_input := first
+	other_input.Input = input_hash(&page_input{Marker: text("m")})
+	gap := first
+	gap.ApiCall = "ListRoles"
+
+	record_pages(t, false,
+		first, journal_entry{Service: "iam", Region: "global", ApiCall: "ListUsers", Input: first.Input, Page: 1, Last: true},
+		other_input,
+		gap, journal_entry{Service: "iam", Region: "global", ApiCall: "ListRoles", Input: gap.Input, Page: 2},
+	)
+	j, err := open_journal(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.close()
+
+	output := reflect.TypeOf(&page_output{})
+	tests := []struct {
+		name     string
+		key      string
+		pages    int
+		complete bool
+	}{
+		{"complete invocation", journal_key("iam", "global", "ListUsers", first.Input), 2, true},
+		{"other input", journal_key("iam", "global", "ListUsers", other_input.Input), 1, false},
+		{"page after a gap", journal_key("iam", "global", "ListRoles", gap.Input), 1, false},
+		{"other region", journal_key("iam", "us-east-1", "ListUsers", first.Input), 0, false},
+	}
+	for _, test := range tests {
+		pages, complete := j.replay(test.key, output)
+		if len(pages) != test.pages || complete != test.complete {
+			t.Errorf("%s: %d pages complete %v, want %d %v", test.name, len(pages), complete, test.pages, test.complete)
+		}
+		for _, page := range pages {
+			if items := page.Interface().(*page_output).Items; len(items) != 1 {
+				t.Errorf("%s: replayed %v", test.name, items)
+			}
+		}
+	}
+	if j.page_count() != 4 {
+		t.Errorf("%d pages loaded, want 4", j.page_count())
+	}
+
+	var nil_journal *journal
+	if pages, complete := nil_journal.replay(tests[0].key, output); pages != nil || complete {
+		t.Error("a run without journal replayed pages")
+	}
+}
+
+func TestJournalInterruptedLine(t *testing.T) {
+	dir := use_run_directory(t)
+	entry := journal_entry{Service: "s3", Region: "global", ApiCall: "ListBuckets", Input: input_hash(&page_input{})}
+	record_pages(t, false, entry)
+
+	path := filepath.Join(dir, JOURNAL_FILE)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"Service": "s3", "Region": "glo`)
+	file.Close()
+
+	j, err := open_journal(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.close()
+	if j.page_count() != 1 {
+		t.Errorf("%d pages loaded, want 1", j.page_count())
+	}
+}
+
+func TestJournalMode(t *testing.T) {
+	dir := use_run_directory(t)
+	path := filepath.Join(dir, JOURNAL_FILE)
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, resume := range []bool{true, false} {
+		os.Chmod(path, 0644)
+		record_pages(t, resume)
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("resume %v: journal mode %o, want 600", resume, mode)
+		}
+	}
+}
+
+func TestPaginateResume(t *testing.T) {
+	use_run_directory(t)
+	pages := three_pages()
+	entry := journal_entry{Service: "test", Region: "us-east-1", ApiCall: "ListItems", Input: input_hash(&page_input{})}
+	j, err := open_journal(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for page := 0; page < 2; page++ {
+		entry.Page = page
+		j.record(entry, &pages[page])
+	}
+	j.close()
+
+	run_journal, err = open_journal(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		run_journal.close()
+		run_journal = nil
+	}()
+
+	// the journaled pages are replayed, the enumeration continues with the third page
+	client := &page_client{pages: pages[2:]}
+	svc := &ServiceMaster{Svc: client, SvcName: "test", Region: "us-east-1"}
+	response, err := svc.paginate(&APICall{Name: "ListItems"}, &page_input{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items := response.(*page_output).Items; !reflect.DeepEqual(items, []string{"a", "b", "c", "d"}) || client.requests != 1 {
+		t.Errorf("got %v after %d requests", items, client.requests)
+	}
+
+	// the invocation is complete in the journal now, nothing is requested again
+	run_journal.close()
+	run_journal, _ = open_journal(true)
+	client = &page_client{}
+	svc.Svc = client
+	if _, err := svc.paginate(&APICall{Name: "ListItems"}, &page_input{}); err != nil || client.requests != 0 {
+		t.Errorf("complete replay sent %d requests: %v", client.requests, err)
+	}
+}
@@ -0,0 +1,114 @@
+package servicemaster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// MANIFEST_FILE describes the run, written to utils.FILEPATH when the enumeration starts and when it ends
+const MANIFEST_FILE = "manifest.json"
+
+// Manifest records who enumerated what and how it went. The identity is filled in by the caller of ServiceCall,
+// the enumerated regions, services and the counts by ServiceCall.
+type Manifest struct {
+	Version          string
+	Account          string
+	Arn              string
+	CredentialSource string
+	AssumedRole      string `json:",omitempty"`
+	Regions          []string
+	Services         []string
+	Start            time.Time
+	End              *time.Time `json:",omitempty"`
+	Resumed          bool       `json:",omitempty"`
+	Stopped          string     `json:",omitempty"` // why an interrupted run stopped (canceled, deadline exceeded)
+	Calls            CallCounts
+	Results          []ServiceTotals
+}
+
+// CallCounts are the totals of the run
+type CallCounts struct {
+	ApiCalls  int   // api calls run, a dependent api call counts once
+	Succeeded int   // api calls with a stored result
+	Failed    int   // api calls with a stored error
+	Requests  int64 // requests sent, pages, retries and every input of the dependent api calls included
+	Replayed  int64 // pages replayed from the journal of the previous run
+}
+
+// ServiceTotals are the api calls of a service in a region
+type ServiceTotals struct {
+	Service   string
+	Region    string
+	Succeeded int
+	Failed    int
+}
+
+// requests_sent counts the requests of the running enumeration
+var requests_sent int64
+
+// SaveManifest writes the manifest to utils.FILEPATH
+func SaveManifest(manifest *Manifest) error {
+	if err := os.MkdirAll(utils.FILEPATH, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(utils.FILEPATH, MANIFEST_FILE), []byte(utils.PackResponse(manifest)), 0644)
+}
+
+// LoadManifest reads the manifest of the run in dir
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, MANIFEST_FILE))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// describe_run records the regions and services about to be enumerated
+func (manifest *Manifest) describe_run(services []*ServiceMaster) {
+	regions := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, svc := range services {
+		regions[svc.Region] = true
+		names[svc.SvcName] = true
+	}
+	manifest.Regions, manifest.Services = sorted_keys(regions), sorted_keys(names)
+}
+
+// count_results adds up the api calls of the enumerated services
+func (manifest *Manifest) count_results(services []*ServiceMaster) {
+	manifest.Calls.ApiCalls, manifest.Calls.Succeeded, manifest.Calls.Failed = 0, 0, 0
+	manifest.Results = nil
+	for _, svc := range services {
+		totals := ServiceTotals{Service: svc.SvcName, Region: svc.Region, Succeeded: svc.result_counter - svc.error_counter, Failed: svc.error_counter}
+		manifest.Results = append(manifest.Results, totals)
+		manifest.Calls.ApiCalls += svc.result_counter
+		manifest.Calls.Succeeded += totals.Succeeded
+		manifest.Calls.Failed += totals.Failed
+	}
+	sort.Slice(manifest.Results, func(i, j int) bool {
+		a, b := manifest.Results[i], manifest.Results[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		return a.Region < b.Region
+	})
+}
+
+func sorted_keys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
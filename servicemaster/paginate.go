@@ -0,0 +1,183 @@
+package servicemaster
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// Pagination token pairs: the field of the response holding the next page token
+// and the field of the input it has to be copied into for the following request
+type token_pair struct {
+	output string
+	input  string
+}
+
+var pagination_tokens = []token_pair{
+	{"NextToken", "NextToken"},
+	{"NextMarker", "Marker"},
+	{"Marker", "Marker"},
+	{"NextContinuationToken", "ContinuationToken"},
+	{"NextPageToken", "PageToken"},
+	{"NextPageMarker", "Marker"},
+	{"PaginationToken", "PaginationToken"},
+	{"Position", "Position"},
+}
+
+// paginate invokes the api call until no next page token is returned (or MaxPages is reached)
+// and merges the slices of all pages into a single response. Every page is journaled, the pages
+// journaled by a previous run are replayed instead of being requested again.
+// A response cut short by the page limit or a failed page keeps the next page token of its last page.
+func (svc *ServiceMaster) paginate(apicall *APICall, input_obj interface{}) (interface{}, error) {
+	method := reflect.ValueOf(svc.Svc).MethodByName(apicall.Name)
+	input := copy_input(input_obj)
+	tokens := page_tokens(apicall)
+	entry := journal_entry{Service: svc.SvcName, Region: svc.Region, ApiCall: apicall.Name, Input: input_hash(input_obj)}
+
+	// the limit of the api call applies when it is stricter than -max-pages
+	max_pages := svc.MaxPages
+	if apicall.MaxPages > 0 && (max_pages <= 0 || apicall.MaxPages < max_pages) {
+		max_pages = apicall.MaxPages
+	}
+
+	var merged reflect.Value
+	merge := func(response reflect.Value) {
+		if !merged.IsValid() {
+			merged = copy_input(response.Interface())
+		} else {
+			merge_page(merged.Elem(), response.Elem())
+		}
+	}
+
+	page := 0
+	var last reflect.Value
+	replayed, complete := run_journal.replay(journal_key(entry.Service, entry.Region, entry.ApiCall, entry.Input), method.Type().Out(0))
+	for _, response := range replayed {
+		merge(response)
+		last = response
+		page++
+		if !next_page(response.Elem(), input.Elem(), tokens) {
+			complete = true
+		}
+	}
+
+	for ; !complete && (max_pages <= 0 || page < max_pages); page++ {
+		response, err := svc.invoke(method, input)
+		if err != nil {
+			// keep whatever the previous pages returned
+			if merged.IsValid() {
+				svc.warn_truncated(apicall, fmt.Sprintf("page %d failed: %s", page+1, err.Message))
+				copy_page_tokens(merged.Elem(), last.Elem(), tokens)
+				return merged.Interface(), nil
+			}
+			return nil, err
+		}
+		merge(response)
+		last = response
+
+		more := next_page(response.Elem(), input.Elem(), tokens)
+		entry.Page, entry.Last = page, !more
+		run_journal.record(entry, response.Interface())
+		if !more {
+			complete = true
+		}
+	}
+
+	if !complete {
+		// the limit of -s3-max-keys is asked for, only the -max-pages limit is reported
+		if apicall.MaxPages <= 0 || max_pages != apicall.MaxPages {
+			svc.warn_truncated(apicall, fmt.Sprintf("stopped after %d pages (-max-pages)", max_pages))
+		}
+		copy_page_tokens(merged.Elem(), last.Elem(), tokens)
+		return merged.Interface(), nil
+	}
+	clear_page_tokens(merged.Elem(), tokens)
+	return merged.Interface(), nil
+}
+
+// warn_truncated reports a response missing its last pages, the stored result keeps the next page token
+func (svc *ServiceMaster) warn_truncated(apicall *APICall, reason string) {
+	fmt.Println(utils.Yellow("Warning:"), utils.Yellow(strings.ToUpper(svc.SvcName)+" ("+svc.Region+") "+apicall.Name+" is incomplete, "+reason))
+}
+
+// page_tokens returns the token pair of the paginator of the api call, or all known pairs
+func page_tokens(apicall *APICall) []token_pair {
+	if apicall.Paginator != nil {
+		return []token_pair{{apicall.Paginator.OutputToken, apicall.Paginator.InputToken}}
+	}
+	return pagination_tokens
+}
+
+// copy_input returns a shallow copy of a struct pointer, so shared input objects are never modified
+func copy_input(obj interface{}) reflect.Value {
+	value := reflect.ValueOf(obj)
+	copied := reflect.New(value.Type().Elem())
+	copied.Elem().Set(value.Elem())
+	return copied
+}
+
+// next_page copies the next page token of the response to the input, returns false on the last page
+func next_page(response, input reflect.Value, tokens []token_pair) bool {
+	if truncated := response.FieldByName("IsTruncated"); truncated.IsValid() {
+		if truncated.Kind() == reflect.Ptr {
+			if truncated.IsNil() || !truncated.Elem().Bool() {
+				return false
+			}
+		} else if truncated.Kind() == reflect.Bool && !truncated.Bool() {
+			return false
+		}
+	}
+
+	for _, token := range tokens {
+		out_field := response.FieldByName(token.output)
+		in_field := input.FieldByName(token.input)
+		if !out_field.IsValid() || !in_field.IsValid() || !in_field.CanSet() {
+			continue
+		}
+		if out_field.Type() != in_field.Type() || out_field.IsZero() {
+			continue
+		}
+		if out_field.Kind() == reflect.Ptr && out_field.Elem().IsZero() {
+			continue
+		}
+		// the same token twice means the api echoes the marker back, stop to avoid a loop
+		if reflect.DeepEqual(out_field.Interface(), in_field.Interface()) {
+			return false
+		}
+		in_field.Set(out_field)
+		return true
+	}
+	return false
+}
+
+// merge_page appends every slice field of the page to the merged response
+func merge_page(merged, page reflect.Value) {
+	for i := 0; i < merged.NumField(); i++ {
+		field := merged.Field(i)
+		if field.Kind() != reflect.Slice || !field.CanSet() {
+			continue
+		}
+		field.Set(reflect.AppendSlice(field, page.Field(i)))
+	}
+}
+
+// copy_page_tokens sets the tokens of the merged response to the tokens of the last page, pointing at the next page
+func copy_page_tokens(merged, last reflect.Value, tokens []token_pair) {
+	for _, token := range tokens {
+		field := merged.FieldByName(token.output)
+		if field.IsValid() && field.CanSet() {
+			field.Set(last.FieldByName(token.output))
+		}
+	}
+}
+
+// clear_page_tokens removes the tokens of the last page from the merged response
+func clear_page_tokens(merged reflect.Value, tokens []token_pair) {
+	for _, token := range tokens {
+		if field := merged.FieldByName(token.output); field.IsValid() && field.CanSet() {
+			field.Set(reflect.Zero(field.Type()))
+		}
+	}
+}
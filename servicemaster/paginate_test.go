@@ -0,0 +1,131 @@
+package servicemaster
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type page_input struct {
+	NextToken *string
+	Marker    *string
+}
+
+type page_output struct {
+	Items     []string
+	NextToken *string
+}
+
+type truncated_output struct {
+	NextToken   *string
+	IsTruncated *bool
+}
+
+// page_client serves its pages in order, a page past the failure index returns an error
+type page_client struct {
+	pages    []page_output
+	fail_at  int
+	requests int
+}
+
+func (client *page_client) ListItems(ctx context.Context, input *page_input) (*page_output, error) {
+	page := client.requests
+	client.requests++
+	if client.fail_at > 0 && page >= client.fail_at {
+		return nil, &CallError{Class: ERROR_ACCESS_DENIED, Message: "denied"}
+	}
+	output := client.pages[page]
+	return &output, nil
+}
+
+func text(value string) *string { return &value }
+func flag(value bool) *bool     { return &value }
+
+func three_pages() []page_output {
+	return []page_output{
+		{Items: []string{"a", "b"}, NextToken: text("t1")},
+		{Items: []string{"c"}, NextToken: text("t2")},
+		{Items: []string{"d"}},
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		response interface{}
+		input    page_input
+		tokens   []token_pair
+		more     bool
+		next     page_input
+	}{
+		{"next token", &page_output{NextToken: text("t1")}, page_input{}, pagination_tokens, true, page_input{NextToken: text("t1")}},
+		{"no token", &page_output{}, page_input{}, pagination_tokens, false, page_input{}},
+		{"empty token", &page_output{NextToken: text("")}, page_input{}, pagination_tokens, false, page_input{}},
+		{"not truncated", &truncated_output{text("t1"), flag(false)}, page_input{}, pagination_tokens, false, page_input{}},
+		{"truncated", &truncated_output{text("t1"), flag(true)}, page_input{}, pagination_tokens, true, page_input{NextToken: text("t1")}},
+		{"truncated unset", &truncated_output{text("t1"), nil}, page_input{}, pagination_tokens, false, page_input{}},
+		{"echoed token", &page_output{NextToken: text("t1")}, page_input{NextToken: text("t1")}, pagination_tokens, false, page_input{NextToken: text("t1")}},
+		{"marker pair", &struct{ NextMarker *string }{text("m1")}, page_input{}, pagination_tokens, true, page_input{Marker: text("m1")}},
+		{"paginator pair", &struct{ Cursor *string }{text("c1")}, page_input{}, []token_pair{{"Cursor", "Marker"}}, true, page_input{Marker: text("c1")}},
+		{"unknown pair", &struct{ Cursor *string }{text("c1")}, page_input{}, pagination_tokens, false, page_input{}},
+	}
+	for _, test := range tests {
+		input := test.input
+		more := next_page(reflect.ValueOf(test.response).Elem(), reflect.ValueOf(&input).Elem(), test.tokens)
+		if more != test.more || !reflect.DeepEqual(input, test.next) {
+			t.Errorf("%s: got %v %+v, want %v %+v", test.name, more, input, test.more, test.next)
+		}
+	}
+}
+
+func TestMergePage(t *testing.T) {
+	merged := page_output{Items: []string{"a"}, NextToken: text("t1")}
+	merge_page(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(page_output{Items: []string{"b", "c"}, NextToken: text("t2")}))
+	if !reflect.DeepEqual(merged.Items, []string{"a", "b", "c"}) || *merged.NextToken != "t1" {
+		t.Errorf("got %v %s", merged.Items, *merged.NextToken)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name      string
+		max_pages int
+		call_max  int
+		fail_at   int
+		items     []string
+		next      *string
+		requests  int
+	}{
+		{"every page", 0, 0, 0, []string{"a", "b", "c", "d"}, nil, 3},
+		{"max pages", 2, 0, 0, []string{"a", "b", "c"}, text("t2"), 2},
+		{"call limit", 0, 1, 0, []string{"a", "b"}, text("t1"), 1},
+		{"stricter limit", 2, 1, 0, []string{"a", "b"}, text("t1"), 1},
+		{"limit above the pages", 5, 0, 0, []string{"a", "b", "c", "d"}, nil, 3},
+		{"failed page", 0, 0, 2, []string{"a", "b", "c"}, text("t2"), 3},
+	}
+	for _, test := range tests {
+		client := &page_client{pages: three_pages(), fail_at: test.fail_at}
+		svc := &ServiceMaster{Svc: client, SvcName: "test", Region: "us-east-1", MaxPages: test.max_pages}
+		response, err := svc.paginate(&APICall{Name: "ListItems", MaxPages: test.call_max}, &page_input{})
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		output := response.(*page_output)
+		if !reflect.DeepEqual(output.Items, test.items) || !reflect.DeepEqual(output.NextToken, test.next) || client.requests != test.requests {
+			t.Errorf("%s: got %v %v after %d requests, want %v %v after %d", test.name, output.Items, output.NextToken, client.requests, test.items, test.next, test.requests)
+		}
+	}
+}
+
+func TestPaginateFirstPageFails(t *testing.T) {
+	if _, err := (&ServiceMaster{Svc: &failing_client{}}).paginate(&APICall{Name: "ListItems"}, &page_input{}); err == nil {
+		t.Error("a failed first page returned no error")
+	}
+}
+
+type failing_client struct{}
+
+func (client *failing_client) ListItems(ctx context.Context, input *page_input) (*page_output, error) {
+	return nil, &CallError{Class: ERROR_ACCESS_DENIED, Message: "denied"}
+}
@@ -0,0 +1,205 @@
+package servicemaster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// Status of an action in the permission map
+const (
+	PERMISSION_ALLOWED      = "allowed"
+	PERMISSION_DENIED       = "denied"
+	PERMISSION_INCONCLUSIVE = "inconclusive"
+)
+
+// PERMISSIONS_FILE is written to utils.FILEPATH at the end of the enumeration
+const PERMISSIONS_FILE = "permissions.json"
+
+// Permission is the outcome of every attempt of an IAM action, across regions
+type Permission struct {
+	Action       string
+	Status       string
+	Allowed      []string `json:",omitempty"` // regions where the call succeeded
+	Denied       []string `json:",omitempty"` // regions where the call was denied
+	Inconclusive []string `json:",omitempty"` // regions where the call failed for another reason
+	Reasons      []string `json:",omitempty"` // error classes of the inconclusive attempts
+}
+
+// call_status is the outcome of a single api call of a service
+type call_status struct {
+	status string
+	reason string
+}
+
+// IAM service prefixes that differ from SvcName
+var iam_prefixes = map[string]string{
+	"accessanalyzer":   "access-analyzer",
+	"apigatewayv2":     "apigateway",
+	"cloudhsmv2":       "cloudhsm",
+	"efs":              "elasticfilesystem",
+	"elb":              "elasticloadbalancing",
+	"elbv2":            "elasticloadbalancing",
+	"mobile":           "mobilehub",
+	"opensearch":       "es",
+	"pinpoint":         "mobiletargeting",
+	"sesv2":            "ses",
+	"ssoadmin":         "sso",
+	"stepfunctions":    "states",
+	"wafv2-cloudfront": "wafv2",
+}
+
+// IAM action names that differ from the api call name, "*" matches every api call of the service
+var iam_actions = map[string]string{
+	"apigateway:*":                       "GET",
+	"apigatewayv2:*":                     "GET",
+	"s3:GetBucketCors":                   "GetBucketCORS",
+	"s3:GetBucketEncryption":             "GetEncryptionConfiguration",
+	"s3:GetBucketLifecycleConfiguration": "GetLifecycleConfiguration",
+	"s3:GetBucketReplication":            "GetReplicationConfiguration",
+	"s3:GetPublicAccessBlock":            "GetBucketPublicAccessBlock",
+	"s3:HeadBucket":                      "ListBucket",
+	"s3:ListBuckets":                     "ListAllMyBuckets",
+	"s3:ListObjectsV2":                   "ListBucket",
+}
+
+// IAMAction returns the IAM action name (iam:ListUsers) of an api call of a service
+func IAMAction(svc_name, apicall_name string) string {
+	prefix, ok := iam_prefixes[svc_name]
+	if !ok {
+		prefix = svc_name
+	}
+	if action, ok := iam_actions[svc_name+":"+apicall_name]; ok {
+		return prefix + ":" + action
+	}
+	if action, ok := iam_actions[svc_name+":*"]; ok {
+		return prefix + ":" + action
+	}
+	return prefix + ":" + apicall_name
+}
+
+// permission_status derives the permission of an api call from its response or error
+func permission_status(response interface{}, err error) call_status {
+	if err != nil {
+		call_err := classify_error(err)
+		if call_err.Class == ERROR_ACCESS_DENIED {
+			return call_status{status: PERMISSION_DENIED, reason: call_err.Class}
+		}
+		return call_status{status: PERMISSION_INCONCLUSIVE, reason: call_err.Class}
+	}
+
+	// a dependent call without resources never sent a request
+	if records, ok := response.([]dependency_record); ok && len(records) == 0 {
+		return call_status{status: PERMISSION_INCONCLUSIVE, reason: "no_resources"}
+	}
+	return call_status{status: PERMISSION_ALLOWED}
+}
+
+// BuildPermissions merges the call statuses of the enumerated services into one permission per action
+func BuildPermissions(services []ServiceMaster) []Permission {
+	by_action := make(map[string]*Permission)
+	for i := range services {
+		svc := &services[i]
+		for _, apicall := range svc.ApiCalls {
+			status, ok := svc.statuses[apicall.Name]
+			if !ok {
+				continue
+			}
+			action := apicall.IAMAction
+			if action == "" {
+				action = IAMAction(svc.SvcName, apicall.Name)
+			}
+			permission, ok := by_action[action]
+			if !ok {
+				permission = &Permission{Action: action}
+				by_action[action] = permission
+			}
+
+			switch status.status {
+			case PERMISSION_ALLOWED:
+				permission.Allowed = append_unique(permission.Allowed, svc.Region)
+			case PERMISSION_DENIED:
+				permission.Denied = append_unique(permission.Denied, svc.Region)
+			default:
+				permission.Inconclusive = append_unique(permission.Inconclusive, svc.Region)
+				permission.Reasons = append_unique(permission.Reasons, status.reason)
+			}
+		}
+	}
+
+	var permissions []Permission
+	for _, permission := range by_action {
+		switch {
+		case len(permission.Allowed) > 0:
+			permission.Status = PERMISSION_ALLOWED
+		case len(permission.Denied) > 0:
+			permission.Status = PERMISSION_DENIED
+		default:
+			permission.Status = PERMISSION_INCONCLUSIVE
+		}
+		permissions = append(permissions, *permission)
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Action < permissions[j].Action })
+	return permissions
+}
+
+// SavePermissions writes the permission map to utils.FILEPATH
+func SavePermissions(permissions []Permission) {
+	if err := os.MkdirAll(utils.FILEPATH, 0755); err != nil {
+		log.Fatalln(utils.Red(err))
+	}
+	ioutil.WriteFile(filepath.Join(utils.FILEPATH, PERMISSIONS_FILE), []byte(utils.PackResponse(permissions)), 0644)
+}
+
+// LoadPermissions reads the permission map saved to utils.FILEPATH
+func LoadPermissions() ([]Permission, error) {
+	data, err := ioutil.ReadFile(filepath.Join(utils.FILEPATH, PERMISSIONS_FILE))
+	if err != nil {
+		return nil, err
+	}
+	var permissions []Permission
+	if err := json.Unmarshal(data, &permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// PrintPermissions prints the allowed and denied actions as a table, inconclusive ones are only counted
+func PrintPermissions(permissions []Permission) {
+	width := len("ACTION")
+	for _, permission := range permissions {
+		if len(permission.Action) > width {
+			width = len(permission.Action)
+		}
+	}
+
+	counts := make(map[string]int)
+	fmt.Printf("\n%-*s  %-8s  %s\n", width, "ACTION", "STATUS", "REGIONS")
+	for _, permission := range permissions {
+		counts[permission.Status]++
+		switch permission.Status {
+		case PERMISSION_ALLOWED:
+			fmt.Printf("%-*s  %s  %s\n", width, permission.Action, utils.Green(fmt.Sprintf("%-8s", permission.Status)), strings.Join(permission.Allowed, ","))
+		case PERMISSION_DENIED:
+			fmt.Printf("%-*s  %s  %s\n", width, permission.Action, utils.Red(fmt.Sprintf("%-8s", permission.Status)), strings.Join(permission.Denied, ","))
+		}
+	}
+	fmt.Println()
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Permissions:"), utils.Green(counts[PERMISSION_ALLOWED]), utils.Yellow("allowed,"), utils.Red(counts[PERMISSION_DENIED]), utils.Yellow("denied,"), counts[PERMISSION_INCONCLUSIVE], utils.Yellow("inconclusive, see"), utils.Yellow(filepath.Join(utils.FILEPATH, PERMISSIONS_FILE)))
+}
+
+func append_unique(list []string, value string) []string {
+	if utils.Find(list, value) {
+		return list
+	}
+	list = append(list, value)
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,70 @@
+package servicemaster
+
+import (
+	"encoding/json"
+
+	"github.com/threatroute66/aws-enumerator/policy"
+)
+
+// Fields of the IAM responses holding URL-encoded policy documents
+// (inline policies, role trust policies, managed policy versions)
+var policy_document_fields = map[string]bool{
+	"PolicyDocument":           true,
+	"AssumeRolePolicyDocument": true,
+	"Document":                 true,
+}
+
+// Services whose stored responses get their policy documents decoded
+var policy_document_services = map[string]bool{
+	"iam": true,
+}
+
+// DecodePolicyDocument URL-decodes and parses a policy document, IAM returns them as URL-encoded JSON
+func DecodePolicyDocument(document string) (interface{}, error) {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(policy.Unescape(document)), &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// result_body returns the response as it is stored in the result file,
+// policy documents are decoded so they can be read (and analyzed) without further processing
+func (svc *ServiceMaster) result_body(response interface{}) interface{} {
+	if !policy_document_services[svc.SvcName] {
+		return response
+	}
+
+	// the raw response stays typed for the dependent api calls, only a generic copy is decoded
+	data, err := json.Marshal(response)
+	if err != nil {
+		return response
+	}
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return response
+	}
+	return decode_policy_documents(body)
+}
+
+// decode_policy_documents walks a generic JSON value and replaces the policy document strings by their parsed value,
+// documents that can't be parsed are kept as they are
+func decode_policy_documents(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if document, ok := field.(string); ok && policy_document_fields[key] {
+				if decoded, err := DecodePolicyDocument(document); err == nil {
+					value[key] = decoded
+				}
+				continue
+			}
+			value[key] = decode_policy_documents(field)
+		}
+	case []interface{}:
+		for i := range value {
+			value[i] = decode_policy_documents(value[i])
+		}
+	}
+	return value
+}
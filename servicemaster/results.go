@@ -0,0 +1,83 @@
+package servicemaster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// StoredRegions returns the region folders written by the enumeration
+func StoredRegions() []string {
+	folders, err := ioutil.ReadDir(utils.FILEPATH)
+	if err != nil {
+		return nil
+	}
+
+	var regions []string
+	for _, folder := range folders {
+		if folder.IsDir() {
+			regions = append(regions, folder.Name())
+		}
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// StoredServices returns the names of the services that have a result (or error) file in the region folder
+func StoredServices(region string, errors bool) []string {
+	dir, suffix := utils.ResultPath(region), ".json"
+	if errors {
+		dir, suffix = utils.ErrorPath(region), "_errors.json"
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var services []string
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), suffix) {
+			continue
+		}
+		services = append(services, strings.TrimSuffix(file.Name(), suffix))
+	}
+	sort.Strings(services)
+	return services
+}
+
+// LoadResults reads the file written by save_result_to_file, api call -> stored response (or error with errors)
+func LoadResults(region, svc string, errors bool) (map[string]json.RawMessage, error) {
+	path := filepath.Join(utils.ResultPath(region), svc+".json")
+	if errors {
+		path = filepath.Join(utils.ErrorPath(region), svc+"_errors.json")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every stored element is itself a packed json object {"ApiCall": response}
+	var stored map[string][]string
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	results := make(map[string]json.RawMessage)
+	for _, packed := range stored[svc] {
+		var calls map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(packed), &calls); err != nil {
+			continue
+		}
+		for name, body := range calls {
+			results[name] = body
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,144 @@
+package servicemaster
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Options control the enumeration pace, zero values are taken from the speed preset
+type Options struct {
+	Speed       int           // 1 slow, 2 normal, 3 fast
+	MaxPages    int           // pages fetched per api call, 0 = unlimited
+	Concurrency int           // api calls running at the same time
+	RPS         float64       // requests per second for the whole run
+	CallTimeout time.Duration // timeout of a single request, 0 = none
+	Resume      bool          // replay the pages journaled by the previous run instead of requesting them again
+}
+
+// speed_preset is the pace of a -speed value, service_rps limits a single service in a single region
+type speed_preset struct {
+	concurrency int
+	rps         float64
+	service_rps float64
+}
+
+var speed_presets = map[int]speed_preset{
+	1: {concurrency: 5, rps: 5, service_rps: 2},
+	2: {concurrency: 20, rps: 20, service_rps: 5},
+	3: {concurrency: 50, rps: 50, service_rps: 10},
+}
+
+// resolve fills the zero values of the options from the speed preset
+func (options Options) resolve() (Options, float64) {
+	preset, ok := speed_presets[options.Speed]
+	if !ok {
+		preset = speed_presets[2]
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = preset.concurrency
+	}
+	if options.RPS <= 0 {
+		options.RPS = preset.rps
+	}
+	return options, math.Min(preset.service_rps, options.RPS)
+}
+
+// worker_pool runs the api calls of all services on a fixed number of goroutines
+type worker_pool struct {
+	jobs chan func()
+}
+
+func new_worker_pool(workers int) *worker_pool {
+	pool := &worker_pool{jobs: make(chan func())}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range pool.jobs {
+				job()
+			}
+		}()
+	}
+	return pool
+}
+
+// submit blocks until a worker picks up the job
+func (pool *worker_pool) submit(job func()) {
+	pool.jobs <- job
+}
+
+func (pool *worker_pool) stop() {
+	close(pool.jobs)
+}
+
+// rate_limiter is a token bucket, a nil limiter does not limit
+type rate_limiter struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func new_rate_limiter(rps float64) *rate_limiter {
+	if rps <= 0 {
+		return nil
+	}
+	burst := math.Max(1, rps)
+	return &rate_limiter{rate: rps, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait takes a token from the bucket, sleeping until one is available or the context is done
+func (limiter *rate_limiter) wait(ctx context.Context) error {
+	if limiter == nil {
+		return ctx.Err()
+	}
+
+	limiter.mutex.Lock()
+	now := time.Now()
+	limiter.tokens = math.Min(limiter.burst, limiter.tokens+now.Sub(limiter.last).Seconds()*limiter.rate)
+	limiter.last = now
+
+	// the token is reserved right away, concurrent callers queue up behind it
+	limiter.tokens--
+	delay := time.Duration(0)
+	if limiter.tokens < 0 {
+		delay = time.Duration(-limiter.tokens / limiter.rate * float64(time.Second))
+	}
+	limiter.mutex.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// The scheduler of the running enumeration, created by ServiceCall
+var (
+	api_call_pool  *worker_pool
+	global_limiter *rate_limiter
+)
+
+// throttle waits for the global and the per service rate limit before a request
+func (svc *ServiceMaster) throttle(ctx context.Context) error {
+	if err := global_limiter.wait(ctx); err != nil {
+		return err
+	}
+	return svc.limiter.wait(ctx)
+}
+
+// run_api_call executes the api call on the worker pool, or directly when no pool is running
+func (svc *ServiceMaster) run_api_call(it int) {
+	if api_call_pool == nil {
+		go svc.apicall_wrapper(it)
+		return
+	}
+	api_call_pool.submit(func() { svc.apicall_wrapper(it) })
+}
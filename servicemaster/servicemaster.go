@@ -6,12 +6,13 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
-	"reflect"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/threatroute66/aws-enumerator/utils"
 )
@@ -19,36 +20,62 @@ import (
 type ServiceMaster struct {
 	Svc     interface{}
 	SvcName string
+	Region  string
 
-	ApiCalls           []map[string]interface{}
+	ApiCalls           []APICall
+	MaxPages           int
+	CallTimeout        time.Duration
+	ctx                context.Context
+	limiter            *rate_limiter
 	json_result_struct map[string][]string
 	json_error_struct  map[string][]string
 
 	api_call_result_channel chan string
 	api_call_error_channel  chan string
 
+	// raw responses of the successful api calls, inputs of the dependent api calls
+	responses       map[string]interface{}
+	statuses        map[string]call_status
+	responses_mutex *sync.Mutex
+
 	result_counter int
 	error_counter  int
 }
 
-func (svc *ServiceMaster) ServiceEnumerator() {
+func (svc *ServiceMaster) ServiceEnumerator(ctx context.Context) {
 	// initialize counters, channels, result struct
-	svc.initialize()
+	svc.initialize(ctx)
+
+	// Dependent api calls run after the stage of their dependency has finished
+	for _, stage := range svc.call_stages() {
+		// An interrupted enumeration launches no further stage, the results gathered so far are saved
+		if ctx.Err() != nil {
+			break
+		}
+		// Api calls run on the shared worker pool, requests are paced by the rate limiters
+		for _, it := range stage {
+			svc.run_api_call(it)
+		}
 
-	// Starting go routines (handles 200 goroutines, then rate limit error of AWS, (recommended number of goroutines is less than 100)
-	for i := 0; i < len(svc.ApiCalls); i++ {
-		go svc.apicall_wrapper(i)
+		// Launch control manager for goroutines
+		svc.control_node(len(stage))
 	}
 
-	// Launch control manager for goroutines
-	svc.control_node()
+	close(svc.api_call_result_channel)
+	close(svc.api_call_error_channel)
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Successful"), utils.Yellow(strings.ToUpper(svc.SvcName)+" ("+svc.Region+"):"), utils.Green(svc.result_counter-svc.error_counter), utils.Yellow("/"), utils.Red(svc.result_counter))
+	if ctx.Err() != nil {
+		fmt.Println(utils.Yellow("Warning:"), utils.Yellow(strings.ToUpper(svc.SvcName)+" ("+svc.Region+") interrupted, saving the partial results"))
+	}
 
 	// Save all gathered results to a json file
 	svc.save_result_to_file()
 	defer wg.Done()
 }
 
-func (svc *ServiceMaster) initialize() {
+func (svc *ServiceMaster) initialize(ctx context.Context) {
+	svc.ctx = ctx
+
 	// reset & init counters
 	svc.error_counter = 0
 	svc.result_counter = 0
@@ -61,19 +88,22 @@ func (svc *ServiceMaster) initialize() {
 	delete(svc.json_result_struct, svc.SvcName)
 	svc.json_result_struct = make(map[string][]string)
 
+	// reset & init raw responses
+	svc.responses = make(map[string]interface{})
+	svc.statuses = make(map[string]call_status)
+	svc.responses_mutex = &sync.Mutex{}
+
 	// reset & init channels
 	svc.api_call_result_channel = make(chan string, len(svc.ApiCalls))
 	svc.api_call_error_channel = make(chan string, len(svc.ApiCalls))
 }
 
-func (svc *ServiceMaster) control_node() {
+func (svc *ServiceMaster) control_node(stage_calls int) {
+	stage_end := svc.result_counter + stage_calls
 	// Handling Goroutine results
 	for {
-		// If all of the api calls were done, close channels and break the loop
-		if svc.result_counter >= len(svc.ApiCalls) {
-			defer close(svc.api_call_result_channel)
-			defer close(svc.api_call_error_channel)
-			fmt.Println(utils.Green("Message: "), utils.Yellow("Successful"), utils.Yellow(strings.ToUpper(svc.SvcName))+utils.Yellow(":"), utils.Green(svc.result_counter-svc.error_counter), utils.Yellow("/"), utils.Red(svc.result_counter))
+		// If all of the api calls of the stage were done, break the loop
+		if svc.result_counter >= stage_end {
 			break
 		}
 
@@ -95,39 +125,60 @@ func (svc *ServiceMaster) control_node() {
 
 func (svc *ServiceMaster) apicall_wrapper(it int) {
 
-	apicall_name := reflect.ValueOf(svc.ApiCalls[it]["apicall"]).Interface().(string)
+	apicall := &svc.ApiCalls[it]
+	apicall_name := apicall.Name
 
-	s := reflect.ValueOf(svc.Svc).MethodByName(apicall_name).Call(
-		[]reflect.Value{
-			reflect.ValueOf(context.TODO()),
-			reflect.ValueOf(svc.ApiCalls[it]["input_obj"]),
-		},
-	)
-	response := s[0].Interface()
-	err := s[1].Interface()
+	var response interface{}
+	var err error
+	if apicall.Dependent() {
+		response, err = svc.dependent_call(it)
+	} else {
+		response, err = svc.paginate(apicall, apicall.Input)
+	}
+	svc.store_response(apicall_name, response, err)
 
 	if err != nil {
-		svc.api_call_error_channel <- utils.PackResponse(map[string]string{apicall_name: err.(error).Error()})
+		svc.api_call_error_channel <- utils.PackResponse(map[string]*CallError{apicall_name: classify_error(err)})
 	} else {
-		svc.api_call_result_channel <- utils.PackResponse(map[string]interface{}{apicall_name: response})
+		svc.api_call_result_channel <- utils.PackResponse(map[string]interface{}{apicall_name: svc.result_body(response)})
 	}
 }
 
-// 1. ADD THE FOLDER CREATION IF NOT EXISTED
+// store_response keeps the raw response for the dependent api calls and the permission status of the call
+func (svc *ServiceMaster) store_response(apicall_name string, response interface{}, err error) {
+	svc.responses_mutex.Lock()
+	defer svc.responses_mutex.Unlock()
+	svc.statuses[apicall_name] = permission_status(response, err)
+	if err == nil {
+		svc.responses[apicall_name] = response
+	}
+}
+
+// stored_response returns the raw response of a successful api call
+func (svc *ServiceMaster) stored_response(apicall_name string) (interface{}, bool) {
+	svc.responses_mutex.Lock()
+	defer svc.responses_mutex.Unlock()
+	response, ok := svc.responses[apicall_name]
+	return response, ok
+}
+
+// save_result_to_file writes results and errors to the folder of the service region
 func (svc *ServiceMaster) save_result_to_file() {
 
-	if _, err := os.Stat(utils.FILEPATH); os.IsNotExist(err) {
-		errDir := os.MkdirAll(utils.FILEPATH, 0755)
+	result_path := utils.ResultPath(svc.Region)
+	if _, err := os.Stat(result_path); os.IsNotExist(err) {
+		errDir := os.MkdirAll(result_path, 0755)
 		if errDir != nil {
 			log.Fatalln(utils.Red(err))
 		}
 	}
 	// results
 	file_results := utils.PackResponse(svc.json_result_struct)
-	ioutil.WriteFile(utils.FILEPATH+svc.SvcName+".json", []byte(file_results), 0644)
+	ioutil.WriteFile(result_path+svc.SvcName+".json", []byte(file_results), 0644)
 
-	if _, err := os.Stat(utils.ERROR_FILEPATH); os.IsNotExist(err) {
-		errDir := os.MkdirAll(utils.ERROR_FILEPATH, 0755)
+	error_path := utils.ErrorPath(svc.Region)
+	if _, err := os.Stat(error_path); os.IsNotExist(err) {
+		errDir := os.MkdirAll(error_path, 0755)
 		if errDir != nil {
 			log.Fatalln(utils.Red(err))
 		}
@@ -135,67 +186,135 @@ func (svc *ServiceMaster) save_result_to_file() {
 
 	// save errors
 	file_errors := utils.PackResponse(svc.json_error_struct)
-	ioutil.WriteFile(utils.ERROR_FILEPATH+svc.SvcName+"_errors.json", []byte(file_errors), 0644)
+	ioutil.WriteFile(error_path+svc.SvcName+"_errors.json", []byte(file_errors), 0644)
 }
 
-func CheckAWSCredentials() bool {
-	if utils.CheckEnvFileExistance() {
-		cfg, err := config.LoadDefaultConfig(context.TODO())
-		if err != nil {
-			fmt.Println(utils.Red("Error:"), utils.Yellow("Unable to load SDK config,"))
-			fmt.Println(utils.Green("Fix:"), utils.Yellow("The problem should be on our side, contact support please"))
-			fmt.Println(utils.Red("Trace:"), utils.Yellow(err))
-			os.Exit(1)
-		}
-
-		sts_svc := sts.NewFromConfig(cfg)
-		_, aws_err := sts_svc.GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
-		if aws_err != nil {
-			fmt.Println(utils.Red("Error:"), utils.Yellow("AWS Credentials are not valid"))
-			fmt.Println(utils.Green("Fix:"), utils.Yellow("Provide AWS Credentials, use `./aws-enumerator cred -h` command"))
-			fmt.Println(utils.Red("Trace:"), utils.Yellow(aws_err))
-			os.Exit(1)
-		}
-		return true
-	} else {
-		return false
+// context returns the context of the running enumeration, the api calls are canceled with it
+func (svc *ServiceMaster) context() context.Context {
+	if svc.ctx == nil {
+		return context.Background()
 	}
+	return svc.ctx
 }
 
-func sleep_delay(i, speed int) {
-	if (i+1)%5 == 0 {
-		time.Sleep(time.Duration(speed) * time.Millisecond)
+// CheckAWSCredentials validates the credentials of the config with sts:GetCallerIdentity and returns the identity
+func CheckAWSCredentials(ctx context.Context, cfg aws.Config) *sts.GetCallerIdentityOutput {
+	sts_svc := sts.NewFromConfig(cfg)
+	identity, aws_err := sts_svc.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if aws_err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("AWS Credentials are not valid"))
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Provide AWS Credentials, use `./aws-enumerator cred -h` command"))
+		fmt.Println(utils.Red("Trace:"), utils.Yellow(aws_err))
+		os.Exit(1)
 	}
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Authenticated as"), utils.Green(aws.ToString(identity.Arn)))
+	return identity
 }
 
 var wg sync.WaitGroup
 
-func ServiceCall(AllAWSServices []ServiceMaster, wanted_services []string, speed int) {
+// ServiceCall enumerates the wanted services. Cancelling the context (interrupt, -timeout) stops the running
+// api calls, the results gathered so far and the permissions are still saved. The manifest is completed
+// with the enumerated regions, services and the counts of the run.
+func ServiceCall(ctx context.Context, AllAWSServices []ServiceMaster, wanted_services []string, options Options, manifest *Manifest) {
 
 	start := time.Now()
-	if utils.Find(wanted_services, "all") {
-		for i := range AllAWSServices {
-			wg.Add(1)
-			go AllAWSServices[i].ServiceEnumerator()
-			sleep_delay(i, speed)
+	options, service_rps := options.resolve()
+	fmt.Println(utils.Green("Message: "), utils.Yellow("Concurrency:"), utils.Green(options.Concurrency), utils.Yellow("Requests per second:"), utils.Green(options.RPS), utils.Yellow("per service:"), utils.Green(service_rps))
+
+	api_call_pool = new_worker_pool(options.Concurrency)
+	global_limiter = new_rate_limiter(options.RPS)
+	defer func() {
+		api_call_pool.stop()
+		api_call_pool, global_limiter = nil, nil
+	}()
+
+	// Every fetched page is journaled, -resume replays the pages of the interrupted run
+	journal, err := open_journal(options.Resume)
+	if err != nil {
+		fmt.Println(utils.Red("Error:"), utils.Yellow("Unable to open the journal"), utils.Red(filepath.Join(utils.FILEPATH, JOURNAL_FILE)))
+		fmt.Println(utils.Red("Trace:"), utils.Yellow(err))
+		os.Exit(1)
+	}
+	if options.Resume {
+		fmt.Println(utils.Green("Message: "), utils.Yellow("Resuming the enumeration, pages in the journal:"), utils.Green(journal.page_count()))
+	}
+	run_journal = journal
+	defer func() {
+		run_journal.close()
+		run_journal = nil
+	}()
+
+	var wanted []*ServiceMaster
+	for i := range AllAWSServices {
+		if utils.Find(wanted_services, "all") || utils.Find(wanted_services, AllAWSServices[i].SvcName) {
+			wanted = append(wanted, &AllAWSServices[i])
 		}
-		wg.Wait()
+	}
 
-	} else {
-		for aws_i := range AllAWSServices {
-			for str_i := range wanted_services {
+	// The manifest is saved before the first request, a killed run still records who enumerated what
+	if manifest.Start.IsZero() {
+		manifest.Start = start
+	}
+	manifest.Resumed = manifest.Resumed || options.Resume
+	manifest.describe_run(wanted)
+	if err := SaveManifest(manifest); err != nil {
+		fmt.Println(utils.Yellow("Warning:"), utils.Yellow("Unable to save the manifest:"), utils.Red(err))
+	}
+	atomic.StoreInt64(&requests_sent, 0)
 
-				if AllAWSServices[aws_i].SvcName == wanted_services[str_i] {
-					wg.Add(1)
-					go AllAWSServices[aws_i].ServiceEnumerator()
-					break
-				}
-			}
+	// Services only coordinate their api calls, at most as many as api calls can run at once
+	service_slots := make(chan struct{}, options.Concurrency)
+	var launched []*ServiceMaster
+	for _, svc := range wanted {
+		svc.MaxPages = options.MaxPages
+		svc.CallTimeout = options.CallTimeout
+		svc.limiter = new_rate_limiter(service_rps)
 
+		// Services waiting for a slot are not started once the enumeration is interrupted
+		select {
+		case service_slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
 		}
-		wg.Wait()
+		launched = append(launched, svc)
+		wg.Add(1)
+		go func(svc *ServiceMaster) {
+			defer func() { <-service_slots }()
+			svc.ServiceEnumerator(ctx)
+		}(svc)
 	}
+	wg.Wait()
+
+	// Permission map of the enumerated actions
+	permissions := BuildPermissions(AllAWSServices)
+	SavePermissions(permissions)
+	PrintPermissions(permissions)
+
+	if options.Resume {
+		fmt.Println(utils.Green("Message: "), utils.Yellow("Pages replayed from the journal:"), utils.Green(atomic.LoadInt64(&journal.replayed)))
+	}
+	if ctx.Err() != nil {
+		fmt.Println(utils.Yellow("Warning:"), utils.Yellow("Enumeration stopped ("+ctx.Err().Error()+"), the partial results were saved"))
+		fmt.Println(utils.Green("Fix:"), utils.Yellow("Run the same command with -resume to continue where it stopped"))
+	}
+
 	t := time.Now()
+	manifest.End = &t
+	manifest.Calls.Requests = atomic.LoadInt64(&requests_sent)
+	manifest.Calls.Replayed = atomic.LoadInt64(&journal.replayed)
+	if ctx.Err() != nil {
+		manifest.Stopped = ctx.Err().Error()
+	} else {
+		manifest.Stopped = ""
+	}
+	manifest.count_results(launched)
+	if err := SaveManifest(manifest); err != nil {
+		fmt.Println(utils.Yellow("Warning:"), utils.Yellow("Unable to save the manifest:"), utils.Red(err))
+	}
+
 	elapsed := t.Sub(start)
 	fmt.Println(utils.Green("Time:"), elapsed)
 }
@@ -0,0 +1,179 @@
+package servicestructs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"gopkg.in/yaml.v2"
+)
+
+// The catalog shipped with the binary, -catalog files are merged on top of it
+//
+//go:embed catalog.json
+var embedded_catalog []byte
+
+// Catalog lists the services and api calls to enumerate
+type Catalog struct {
+	Services []CatalogService `json:"services"`
+}
+
+// CatalogService is a service of the catalog, its name is the name of a registered SDK client
+type CatalogService struct {
+	Name     string        `json:"name"`
+	Global   string        `json:"global,omitempty"` // endpoint region of a global service, empty for regional services
+	Disabled bool          `json:"disabled,omitempty"`
+	Calls    []CatalogCall `json:"calls"`
+}
+
+// CatalogCall is an api call of a service. Input holds the default input parameters,
+// DependsOn / Foreach / Params the dependency rules (see servicemaster/dependencies.go).
+// ReadOnly and IAMAction default to servicemaster.IsReadOnly and servicemaster.IAMAction.
+type CatalogCall struct {
+	Name      string                   `json:"name"`
+	Input     json.RawMessage          `json:"input,omitempty"`
+	DependsOn string                   `json:"depends_on,omitempty"`
+	Foreach   string                   `json:"foreach,omitempty"`
+	Params    map[string]string        `json:"params,omitempty"`
+	Paginator *servicemaster.Paginator `json:"paginator,omitempty"`
+	ReadOnly  *bool                    `json:"read_only,omitempty"`
+	IAMAction string                   `json:"iam_action,omitempty"`
+	Tags      []string                 `json:"tags,omitempty"`
+	Disabled  bool                     `json:"disabled,omitempty"`
+}
+
+// LoadCatalog parses the embedded catalog, merges the override file into it (if any) and drops the disabled entries.
+// The override file is JSON, or YAML when its extension is .yaml or .yml.
+func LoadCatalog(override string) (*Catalog, error) {
+	catalog := &Catalog{}
+	if err := json.Unmarshal(embedded_catalog, catalog); err != nil {
+		return nil, fmt.Errorf("embedded catalog: %v", err)
+	}
+
+	if override != "" {
+		data, err := ioutil.ReadFile(override)
+		if err != nil {
+			return nil, err
+		}
+		if ext := strings.ToLower(filepath.Ext(override)); ext == ".yaml" || ext == ".yml" {
+			if data, err = yaml_to_json(data); err != nil {
+				return nil, fmt.Errorf("%s: %v", override, err)
+			}
+		}
+		user_catalog := &Catalog{}
+		if err := json.Unmarshal(data, user_catalog); err != nil {
+			return nil, fmt.Errorf("%s: %v", override, err)
+		}
+		catalog.merge(user_catalog)
+	}
+
+	catalog.drop_disabled()
+	return catalog, nil
+}
+
+// yaml_to_json converts a YAML document to JSON, so both formats are decoded with the json tags of the catalog
+// and the inputs stay raw JSON for the SDK input types
+func yaml_to_json(data []byte) ([]byte, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	value, err := json_value(document)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// json_value converts the map[interface{}]interface{} mappings of yaml.v2 to JSON objects
+func json_value(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("mapping key %v is not a string", key)
+			}
+			converted, err := json_value(item)
+			if err != nil {
+				return nil, err
+			}
+			object[name] = converted
+		}
+		return object, nil
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, item := range value {
+			converted, err := json_value(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = converted
+		}
+		return list, nil
+	}
+	return value, nil
+}
+
+// merge applies the override: services and calls are matched by name, a matching call is replaced,
+// new services and calls are appended, "disabled": true turns a service or a call off
+func (catalog *Catalog) merge(override *Catalog) {
+	for _, override_svc := range override.Services {
+		i := catalog.service_index(override_svc.Name)
+		if i < 0 {
+			catalog.Services = append(catalog.Services, override_svc)
+			continue
+		}
+
+		svc := &catalog.Services[i]
+		svc.Disabled = override_svc.Disabled
+		if override_svc.Global != "" {
+			svc.Global = override_svc.Global
+		}
+		for _, override_call := range override_svc.Calls {
+			replaced := false
+			for j := range svc.Calls {
+				if svc.Calls[j].Name == override_call.Name {
+					svc.Calls[j] = override_call
+					replaced = true
+				}
+			}
+			if !replaced {
+				svc.Calls = append(svc.Calls, override_call)
+			}
+		}
+	}
+}
+
+// drop_disabled removes the disabled services and api calls
+func (catalog *Catalog) drop_disabled() {
+	var services []CatalogService
+	for _, svc := range catalog.Services {
+		if svc.Disabled {
+			continue
+		}
+		var calls []CatalogCall
+		for _, call := range svc.Calls {
+			if !call.Disabled {
+				calls = append(calls, call)
+			}
+		}
+		svc.Calls = calls
+		services = append(services, svc)
+	}
+	catalog.Services = services
+}
+
+func (catalog *Catalog) service_index(name string) int {
+	for i := range catalog.Services {
+		if catalog.Services[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,67 @@
+package servicestructs
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadCatalogFormats(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"override.json": `{"services": [{"name": "iam", "calls": [{"name": "ListUsers", "input": {"MaxItems": 100}}, {"name": "ListRoles", "disabled": true}]}, {"name": "xray", "disabled": true}]}`,
+		"override.yaml": "services:\n  - name: iam\n    calls:\n      - name: ListUsers\n        input: {MaxItems: 100}\n      - {name: ListRoles, disabled: true}\n  - name: xray\n    disabled: true\n",
+	}
+	var catalogs []*Catalog
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		catalog, err := LoadCatalog(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		// inputs are raw JSON, compared without their spacing
+		for i := range catalog.Services {
+			for j := range catalog.Services[i].Calls {
+				call := &catalog.Services[i].Calls[j]
+				if len(call.Input) > 0 {
+					var compacted bytes.Buffer
+					json.Compact(&compacted, call.Input)
+					call.Input = compacted.Bytes()
+				}
+			}
+		}
+		catalogs = append(catalogs, catalog)
+
+		if catalog.service_index("xray") >= 0 {
+			t.Errorf("%s: disabled service kept", name)
+		}
+		iam := catalog.Services[catalog.service_index("iam")]
+		for _, call := range iam.Calls {
+			if call.Name == "ListRoles" {
+				t.Errorf("%s: disabled call kept", name)
+			}
+			if call.Name == "ListUsers" && string(call.Input) != `{"MaxItems":100}` {
+				t.Errorf("%s: input %s", name, call.Input)
+			}
+		}
+	}
+	if !reflect.DeepEqual(catalogs[0], catalogs[1]) {
+		t.Error("the JSON and YAML overrides load different catalogs")
+	}
+}
+
+func TestLoadCatalogInvalidYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "override.yml")
+	if err := os.WriteFile(path, []byte("services: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCatalog(path); err == nil {
+		t.Error("invalid YAML loaded")
+	}
+}
@@ -0,0 +1,283 @@
+package servicestructs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
+	"github.com/aws/aws-sdk-go-v2/service/amplify"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/appconfig"
+	"github.com/aws/aws-sdk-go-v2/service/appmesh"
+	"github.com/aws/aws-sdk-go-v2/service/appsync"
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/backup"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	"github.com/aws/aws-sdk-go-v2/service/bedrock"
+	"github.com/aws/aws-sdk-go-v2/service/chime"
+	"github.com/aws/aws-sdk-go-v2/service/cloud9"
+	"github.com/aws/aws-sdk-go-v2/service/clouddirectory"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudhsm"
+	"github.com/aws/aws-sdk-go-v2/service/cloudhsmv2"
+	"github.com/aws/aws-sdk-go-v2/service/cloudsearch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/codeartifact"
+	"github.com/aws/aws-sdk-go-v2/service/codebuild"
+	"github.com/aws/aws-sdk-go-v2/service/codecommit"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	"github.com/aws/aws-sdk-go-v2/service/codepipeline"
+	"github.com/aws/aws-sdk-go-v2/service/codestar"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentity"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/comprehend"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/datapipeline"
+	"github.com/aws/aws-sdk-go-v2/service/datasync"
+	"github.com/aws/aws-sdk-go-v2/service/dax"
+	"github.com/aws/aws-sdk-go-v2/service/devicefarm"
+	"github.com/aws/aws-sdk-go-v2/service/directconnect"
+	"github.com/aws/aws-sdk-go-v2/service/dlm"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecrpublic"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/elastictranscoder"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/fms"
+	"github.com/aws/aws-sdk-go-v2/service/fsx"
+	"github.com/aws/aws-sdk-go-v2/service/gamelift"
+	"github.com/aws/aws-sdk-go-v2/service/globalaccelerator"
+	"github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/greengrass"
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
+	"github.com/aws/aws-sdk-go-v2/service/health"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/identitystore"
+	"github.com/aws/aws-sdk-go-v2/service/inspector"
+	"github.com/aws/aws-sdk-go-v2/service/inspector2"
+	"github.com/aws/aws-sdk-go-v2/service/iot"
+	"github.com/aws/aws-sdk-go-v2/service/iotanalytics"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesisanalytics"
+	"github.com/aws/aws-sdk-go-v2/service/kinesisvideo"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lightsail"
+	"github.com/aws/aws-sdk-go-v2/service/machinelearning"
+	"github.com/aws/aws-sdk-go-v2/service/macie"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconnect"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert"
+	"github.com/aws/aws-sdk-go-v2/service/medialive"
+	"github.com/aws/aws-sdk-go-v2/service/mediapackage"
+	"github.com/aws/aws-sdk-go-v2/service/mediastore"
+	"github.com/aws/aws-sdk-go-v2/service/mediatailor"
+	"github.com/aws/aws-sdk-go-v2/service/mobile"
+	"github.com/aws/aws-sdk-go-v2/service/mq"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+	"github.com/aws/aws-sdk-go-v2/service/opsworks"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/pinpoint"
+	"github.com/aws/aws-sdk-go-v2/service/polly"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/ram"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/redshift"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition"
+	"github.com/aws/aws-sdk-go-v2/service/robomaker"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53domains"
+	"github.com/aws/aws-sdk-go-v2/service/route53resolver"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/servicecatalog"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/aws/aws-sdk-go-v2/service/shield"
+	"github.com/aws/aws-sdk-go-v2/service/signer"
+	"github.com/aws/aws-sdk-go-v2/service/sms"
+	"github.com/aws/aws-sdk-go-v2/service/snowball"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssoadmin"
+	"github.com/aws/aws-sdk-go-v2/service/storagegateway"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/support"
+	"github.com/aws/aws-sdk-go-v2/service/transcribe"
+	"github.com/aws/aws-sdk-go-v2/service/transfer"
+	"github.com/aws/aws-sdk-go-v2/service/translate"
+	"github.com/aws/aws-sdk-go-v2/service/waf"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
+	"github.com/aws/aws-sdk-go-v2/service/workdocs"
+	"github.com/aws/aws-sdk-go-v2/service/worklink"
+	"github.com/aws/aws-sdk-go-v2/service/workmail"
+	"github.com/aws/aws-sdk-go-v2/service/workspaces"
+	"github.com/aws/aws-sdk-go-v2/service/xray"
+)
+
+// client_factory creates the SDK client of a service for a config
+type client_factory func(cfg aws.Config) interface{}
+
+// clients registers the SDK clients the catalog entries are resolved against, by service name
+var clients = map[string]client_factory{
+	"accessanalyzer":    func(cfg aws.Config) interface{} { return accessanalyzer.NewFromConfig(cfg) },
+	"acm":               func(cfg aws.Config) interface{} { return acm.NewFromConfig(cfg) },
+	"amplify":           func(cfg aws.Config) interface{} { return amplify.NewFromConfig(cfg) },
+	"apigateway":        func(cfg aws.Config) interface{} { return apigateway.NewFromConfig(cfg) },
+	"apigatewayv2":      func(cfg aws.Config) interface{} { return apigatewayv2.NewFromConfig(cfg) },
+	"appconfig":         func(cfg aws.Config) interface{} { return appconfig.NewFromConfig(cfg) },
+	"appmesh":           func(cfg aws.Config) interface{} { return appmesh.NewFromConfig(cfg) },
+	"appsync":           func(cfg aws.Config) interface{} { return appsync.NewFromConfig(cfg) },
+	"athena":            func(cfg aws.Config) interface{} { return athena.NewFromConfig(cfg) },
+	"autoscaling":       func(cfg aws.Config) interface{} { return autoscaling.NewFromConfig(cfg) },
+	"backup":            func(cfg aws.Config) interface{} { return backup.NewFromConfig(cfg) },
+	"batch":             func(cfg aws.Config) interface{} { return batch.NewFromConfig(cfg) },
+	"bedrock":           func(cfg aws.Config) interface{} { return bedrock.NewFromConfig(cfg) },
+	"chime":             func(cfg aws.Config) interface{} { return chime.NewFromConfig(cfg) },
+	"cloud9":            func(cfg aws.Config) interface{} { return cloud9.NewFromConfig(cfg) },
+	"clouddirectory":    func(cfg aws.Config) interface{} { return clouddirectory.NewFromConfig(cfg) },
+	"cloudformation":    func(cfg aws.Config) interface{} { return cloudformation.NewFromConfig(cfg) },
+	"cloudfront":        func(cfg aws.Config) interface{} { return cloudfront.NewFromConfig(cfg) },
+	"cloudhsm":          func(cfg aws.Config) interface{} { return cloudhsm.NewFromConfig(cfg) },
+	"cloudhsmv2":        func(cfg aws.Config) interface{} { return cloudhsmv2.NewFromConfig(cfg) },
+	"cloudsearch":       func(cfg aws.Config) interface{} { return cloudsearch.NewFromConfig(cfg) },
+	"cloudtrail":        func(cfg aws.Config) interface{} { return cloudtrail.NewFromConfig(cfg) },
+	"cloudwatch":        func(cfg aws.Config) interface{} { return cloudwatch.NewFromConfig(cfg) },
+	"codeartifact":      func(cfg aws.Config) interface{} { return codeartifact.NewFromConfig(cfg) },
+	"codebuild":         func(cfg aws.Config) interface{} { return codebuild.NewFromConfig(cfg) },
+	"codecommit":        func(cfg aws.Config) interface{} { return codecommit.NewFromConfig(cfg) },
+	"codedeploy":        func(cfg aws.Config) interface{} { return codedeploy.NewFromConfig(cfg) },
+	"codepipeline":      func(cfg aws.Config) interface{} { return codepipeline.NewFromConfig(cfg) },
+	"codestar":          func(cfg aws.Config) interface{} { return codestar.NewFromConfig(cfg) },
+	"cognito-identity":  func(cfg aws.Config) interface{} { return cognitoidentity.NewFromConfig(cfg) },
+	"cognito-idp":       func(cfg aws.Config) interface{} { return cognitoidentityprovider.NewFromConfig(cfg) },
+	"comprehend":        func(cfg aws.Config) interface{} { return comprehend.NewFromConfig(cfg) },
+	"config":            func(cfg aws.Config) interface{} { return configservice.NewFromConfig(cfg) },
+	"datapipeline":      func(cfg aws.Config) interface{} { return datapipeline.NewFromConfig(cfg) },
+	"datasync":          func(cfg aws.Config) interface{} { return datasync.NewFromConfig(cfg) },
+	"dax":               func(cfg aws.Config) interface{} { return dax.NewFromConfig(cfg) },
+	"devicefarm":        func(cfg aws.Config) interface{} { return devicefarm.NewFromConfig(cfg) },
+	"directconnect":     func(cfg aws.Config) interface{} { return directconnect.NewFromConfig(cfg) },
+	"dlm":               func(cfg aws.Config) interface{} { return dlm.NewFromConfig(cfg) },
+	"dynamodb":          func(cfg aws.Config) interface{} { return dynamodb.NewFromConfig(cfg) },
+	"ec2":               func(cfg aws.Config) interface{} { return ec2.NewFromConfig(cfg) },
+	"ecr":               func(cfg aws.Config) interface{} { return ecr.NewFromConfig(cfg) },
+	"ecr-public":        func(cfg aws.Config) interface{} { return ecrpublic.NewFromConfig(cfg) },
+	"ecs":               func(cfg aws.Config) interface{} { return ecs.NewFromConfig(cfg) },
+	"efs":               func(cfg aws.Config) interface{} { return efs.NewFromConfig(cfg) },
+	"eks":               func(cfg aws.Config) interface{} { return eks.NewFromConfig(cfg) },
+	"elasticache":       func(cfg aws.Config) interface{} { return elasticache.NewFromConfig(cfg) },
+	"elasticbeanstalk":  func(cfg aws.Config) interface{} { return elasticbeanstalk.NewFromConfig(cfg) },
+	"elastictranscoder": func(cfg aws.Config) interface{} { return elastictranscoder.NewFromConfig(cfg) },
+	"elb":               func(cfg aws.Config) interface{} { return elasticloadbalancing.NewFromConfig(cfg) },
+	"elbv2":             func(cfg aws.Config) interface{} { return elasticloadbalancingv2.NewFromConfig(cfg) },
+	"events":            func(cfg aws.Config) interface{} { return eventbridge.NewFromConfig(cfg) },
+	"firehose":          func(cfg aws.Config) interface{} { return firehose.NewFromConfig(cfg) },
+	"fms":               func(cfg aws.Config) interface{} { return fms.NewFromConfig(cfg) },
+	"fsx":               func(cfg aws.Config) interface{} { return fsx.NewFromConfig(cfg) },
+	"gamelift":          func(cfg aws.Config) interface{} { return gamelift.NewFromConfig(cfg) },
+	"globalaccelerator": func(cfg aws.Config) interface{} { return globalaccelerator.NewFromConfig(cfg) },
+	"glue":              func(cfg aws.Config) interface{} { return glue.NewFromConfig(cfg) },
+	"greengrass":        func(cfg aws.Config) interface{} { return greengrass.NewFromConfig(cfg) },
+	"guardduty":         func(cfg aws.Config) interface{} { return guardduty.NewFromConfig(cfg) },
+	"health":            func(cfg aws.Config) interface{} { return health.NewFromConfig(cfg) },
+	"iam":               func(cfg aws.Config) interface{} { return iam.NewFromConfig(cfg) },
+	"identitystore":     func(cfg aws.Config) interface{} { return new_identitystore_client(cfg) },
+	"inspector":         func(cfg aws.Config) interface{} { return inspector.NewFromConfig(cfg) },
+	"inspector2":        func(cfg aws.Config) interface{} { return inspector2.NewFromConfig(cfg) },
+	"iot":               func(cfg aws.Config) interface{} { return iot.NewFromConfig(cfg) },
+	"iotanalytics":      func(cfg aws.Config) interface{} { return iotanalytics.NewFromConfig(cfg) },
+	"kafka":             func(cfg aws.Config) interface{} { return kafka.NewFromConfig(cfg) },
+	"kinesis":           func(cfg aws.Config) interface{} { return kinesis.NewFromConfig(cfg) },
+	"kinesisanalytics":  func(cfg aws.Config) interface{} { return kinesisanalytics.NewFromConfig(cfg) },
+	"kinesisvideo":      func(cfg aws.Config) interface{} { return kinesisvideo.NewFromConfig(cfg) },
+	"kms":               func(cfg aws.Config) interface{} { return kms.NewFromConfig(cfg) },
+	"lambda":            func(cfg aws.Config) interface{} { return lambda.NewFromConfig(cfg) },
+	"lightsail":         func(cfg aws.Config) interface{} { return lightsail.NewFromConfig(cfg) },
+	"logs":              func(cfg aws.Config) interface{} { return cloudwatchlogs.NewFromConfig(cfg) },
+	"machinelearning":   func(cfg aws.Config) interface{} { return machinelearning.NewFromConfig(cfg) },
+	"macie":             func(cfg aws.Config) interface{} { return macie.NewFromConfig(cfg) },
+	"mediaconnect":      func(cfg aws.Config) interface{} { return mediaconnect.NewFromConfig(cfg) },
+	"mediaconvert":      func(cfg aws.Config) interface{} { return mediaconvert.NewFromConfig(cfg) },
+	"medialive":         func(cfg aws.Config) interface{} { return medialive.NewFromConfig(cfg) },
+	"mediapackage":      func(cfg aws.Config) interface{} { return mediapackage.NewFromConfig(cfg) },
+	"mediastore":        func(cfg aws.Config) interface{} { return mediastore.NewFromConfig(cfg) },
+	"mediatailor":       func(cfg aws.Config) interface{} { return mediatailor.NewFromConfig(cfg) },
+	"mobile":            func(cfg aws.Config) interface{} { return mobile.NewFromConfig(cfg) },
+	"mq":                func(cfg aws.Config) interface{} { return mq.NewFromConfig(cfg) },
+	"opensearch":        func(cfg aws.Config) interface{} { return opensearch.NewFromConfig(cfg) },
+	"opsworks":          func(cfg aws.Config) interface{} { return opsworks.NewFromConfig(cfg) },
+	"organizations":     func(cfg aws.Config) interface{} { return organizations.NewFromConfig(cfg) },
+	"pinpoint":          func(cfg aws.Config) interface{} { return pinpoint.NewFromConfig(cfg) },
+	"polly":             func(cfg aws.Config) interface{} { return polly.NewFromConfig(cfg) },
+	"pricing":           func(cfg aws.Config) interface{} { return pricing.NewFromConfig(cfg) },
+	"ram":               func(cfg aws.Config) interface{} { return ram.NewFromConfig(cfg) },
+	"rds":               func(cfg aws.Config) interface{} { return rds.NewFromConfig(cfg) },
+	"redshift":          func(cfg aws.Config) interface{} { return redshift.NewFromConfig(cfg) },
+	"rekognition":       func(cfg aws.Config) interface{} { return rekognition.NewFromConfig(cfg) },
+	"robomaker":         func(cfg aws.Config) interface{} { return robomaker.NewFromConfig(cfg) },
+	"route53":           func(cfg aws.Config) interface{} { return route53.NewFromConfig(cfg) },
+	"route53domains":    func(cfg aws.Config) interface{} { return route53domains.NewFromConfig(cfg) },
+	"route53resolver":   func(cfg aws.Config) interface{} { return route53resolver.NewFromConfig(cfg) },
+	"s3":                func(cfg aws.Config) interface{} { return new_s3_client(cfg) },
+	"sagemaker":         func(cfg aws.Config) interface{} { return sagemaker.NewFromConfig(cfg) },
+	"secretsmanager":    func(cfg aws.Config) interface{} { return secretsmanager.NewFromConfig(cfg) },
+	"securityhub":       func(cfg aws.Config) interface{} { return securityhub.NewFromConfig(cfg) },
+	"servicecatalog":    func(cfg aws.Config) interface{} { return servicecatalog.NewFromConfig(cfg) },
+	"sesv2":             func(cfg aws.Config) interface{} { return sesv2.NewFromConfig(cfg) },
+	"shield":            func(cfg aws.Config) interface{} { return shield.NewFromConfig(cfg) },
+	"signer":            func(cfg aws.Config) interface{} { return signer.NewFromConfig(cfg) },
+	"sms":               func(cfg aws.Config) interface{} { return sms.NewFromConfig(cfg) },
+	"snowball":          func(cfg aws.Config) interface{} { return snowball.NewFromConfig(cfg) },
+	"sns":               func(cfg aws.Config) interface{} { return sns.NewFromConfig(cfg) },
+	"sqs":               func(cfg aws.Config) interface{} { return sqs.NewFromConfig(cfg) },
+	"ssm":               func(cfg aws.Config) interface{} { return ssm.NewFromConfig(cfg) },
+	"ssoadmin":          func(cfg aws.Config) interface{} { return ssoadmin.NewFromConfig(cfg) },
+	"stepfunctions":     func(cfg aws.Config) interface{} { return sfn.NewFromConfig(cfg) },
+	"storagegateway":    func(cfg aws.Config) interface{} { return storagegateway.NewFromConfig(cfg) },
+	"sts":               func(cfg aws.Config) interface{} { return sts.NewFromConfig(cfg) },
+	"support":           func(cfg aws.Config) interface{} { return support.NewFromConfig(cfg) },
+	"transcribe":        func(cfg aws.Config) interface{} { return transcribe.NewFromConfig(cfg) },
+	"transfer":          func(cfg aws.Config) interface{} { return transfer.NewFromConfig(cfg) },
+	"translate":         func(cfg aws.Config) interface{} { return translate.NewFromConfig(cfg) },
+	"waf":               func(cfg aws.Config) interface{} { return waf.NewFromConfig(cfg) },
+	"wafv2":             func(cfg aws.Config) interface{} { return wafv2.NewFromConfig(cfg) },
+	"wafv2-cloudfront":  func(cfg aws.Config) interface{} { return wafv2.NewFromConfig(cfg) }, // CLOUDFRONT scope, us-east-1 only
+	"workdocs":          func(cfg aws.Config) interface{} { return workdocs.NewFromConfig(cfg) },
+	"worklink":          func(cfg aws.Config) interface{} { return worklink.NewFromConfig(cfg) },
+	"workmail":          func(cfg aws.Config) interface{} { return workmail.NewFromConfig(cfg) },
+	"workspaces":        func(cfg aws.Config) interface{} { return workspaces.NewFromConfig(cfg) },
+	"xray":              func(cfg aws.Config) interface{} { return xray.NewFromConfig(cfg) },
+}
+
+// identitystore_client adds ssoadmin:ListInstances to the identitystore client,
+// the identity store ids of the instances drive the identitystore api calls
+type identitystore_client struct {
+	*identitystore.Client
+	ssoadmin *ssoadmin.Client
+}
+
+func new_identitystore_client(cfg aws.Config) *identitystore_client {
+	return &identitystore_client{Client: identitystore.NewFromConfig(cfg), ssoadmin: ssoadmin.NewFromConfig(cfg)}
+}
+
+func (client *identitystore_client) ListInstances(ctx context.Context, params *ssoadmin.ListInstancesInput, optFns ...func(*ssoadmin.Options)) (*ssoadmin.ListInstancesOutput, error) {
+	return client.ssoadmin.ListInstances(ctx, params, optFns...)
+}
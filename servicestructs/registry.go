@@ -0,0 +1,166 @@
+package servicestructs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// Registry holds the validated api calls of the catalog, resolved against the registered SDK clients
+type Registry struct {
+	Services []RegisteredService
+}
+
+// RegisteredService is a service of the registry with its typed api calls
+type RegisteredService struct {
+	Name     string
+	Global   string // endpoint region of a global service, empty for regional services
+	ApiCalls []servicemaster.APICall
+	factory  client_factory
+}
+
+// NewRegistry resolves the catalog against the SDK clients without sending any request.
+// Every problem (unknown service or method, mismatched input, broken dependency rule...) is returned
+// and the api calls with problems are left out of the registry.
+func NewRegistry(catalog *Catalog) (*Registry, []error) {
+	registry := &Registry{}
+	var problems []error
+	for _, catalog_svc := range catalog.Services {
+		factory, ok := clients[catalog_svc.Name]
+		if !ok {
+			problems = append(problems, fmt.Errorf("%s: no SDK client registered for the service", catalog_svc.Name))
+			continue
+		}
+
+		client := factory(aws.Config{})
+		var apicalls []servicemaster.APICall
+		for _, call := range catalog_svc.Calls {
+			apicall, err := new_apicall(catalog_svc.Name, client, call)
+			if err != nil {
+				problems = append(problems, &servicemaster.APICallError{Service: catalog_svc.Name, ApiCall: call.Name, Message: err.Error()})
+				continue
+			}
+			apicalls = append(apicalls, apicall)
+		}
+
+		invalid := make(map[string]bool)
+		for _, call_err := range servicemaster.ValidateAPICalls(catalog_svc.Name, client, apicalls) {
+			problems = append(problems, call_err)
+			invalid[call_err.ApiCall] = true
+		}
+
+		svc := RegisteredService{Name: catalog_svc.Name, Global: catalog_svc.Global, factory: factory}
+		for _, apicall := range apicalls {
+			if !invalid[apicall.Name] {
+				svc.ApiCalls = append(svc.ApiCalls, apicall)
+			}
+		}
+		registry.Services = append(registry.Services, svc)
+	}
+	return registry, problems
+}
+
+// new_apicall builds the typed api call of a catalog entry, the input is decoded into the input type of the method
+func new_apicall(svc_name string, client interface{}, call CatalogCall) (servicemaster.APICall, error) {
+	apicall := servicemaster.APICall{
+		Name:      call.Name,
+		Paginator: call.Paginator,
+		ReadOnly:  servicemaster.IsReadOnly(call.Name),
+		IAMAction: call.IAMAction,
+		Tags:      call.Tags,
+		DependsOn: call.DependsOn,
+		Foreach:   call.Foreach,
+		Params:    call.Params,
+	}
+	if call.ReadOnly != nil {
+		apicall.ReadOnly = *call.ReadOnly
+	}
+	if apicall.IAMAction == "" {
+		apicall.IAMAction = servicemaster.IAMAction(svc_name, call.Name)
+	}
+
+	method, ok := reflect.TypeOf(client).MethodByName(call.Name)
+	if !ok || method.Type.NumIn() < 3 || method.Type.In(2).Kind() != reflect.Ptr {
+		return apicall, fmt.Errorf("unknown api call of %T", client)
+	}
+
+	input := reflect.New(method.Type.In(2).Elem()).Interface()
+	if len(call.Input) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(call.Input))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(input); err != nil {
+			return apicall, fmt.Errorf("input does not match %T: %v", input, err)
+		}
+	}
+	apicall.Input = input
+	return apicall, nil
+}
+
+// Count returns the number of services and api calls of the registry
+func (registry *Registry) Count() (int, int) {
+	apicalls := 0
+	for _, svc := range registry.Services {
+		apicalls += len(svc.ApiCalls)
+	}
+	return len(registry.Services), apicalls
+}
+
+// IAMActions returns the distinct IAM actions of the api calls of the registry
+func (registry *Registry) IAMActions() []string {
+	var actions []string
+	for _, svc := range registry.Services {
+		for _, apicall := range svc.ApiCalls {
+			if !utils.Find(actions, apicall.IAMAction) {
+				actions = append(actions, apicall.IAMAction)
+			}
+		}
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// globalServices maps the global services to the region of their endpoint
+func (registry *Registry) globalServices() map[string]string {
+	globals := make(map[string]string)
+	for _, svc := range registry.Services {
+		if svc.Global != "" {
+			globals[svc.Name] = svc.Global
+		}
+	}
+	return globals
+}
+
+// globalEndpointRegions returns the distinct endpoint regions of the global services
+func (registry *Registry) globalEndpointRegions() []string {
+	var regions []string
+	for _, region := range registry.globalServices() {
+		if !utils.Find(regions, region) {
+			regions = append(regions, region)
+		}
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// regionServices creates the services of the registry with clients bound to a single region
+func (registry *Registry) regionServices(cfg aws.Config, region string) []servicemaster.ServiceMaster {
+	cfg = cfg.Copy()
+	cfg.Region = region
+
+	var services []servicemaster.ServiceMaster
+	for _, svc := range registry.Services {
+		services = append(services, servicemaster.ServiceMaster{
+			Svc:      svc.factory(cfg),
+			SvcName:  svc.Name,
+			Region:   region,
+			ApiCalls: append([]servicemaster.APICall(nil), svc.ApiCalls...),
+		})
+	}
+	return services
+}
@@ -0,0 +1,171 @@
+package servicestructs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/threatroute66/aws-enumerator/servicemaster"
+	"github.com/threatroute66/aws-enumerator/utils"
+)
+
+// S3_OBJECTS_TAG marks the object listing calls, they only run with -s3-max-keys
+const S3_OBJECTS_TAG = "s3_objects"
+
+// s3_page_size is the largest MaxKeys accepted by ListObjectsV2
+const s3_page_size = 1000
+
+// s3_client sends the bucket level api calls to the region of the bucket.
+// Buckets live in a single region, a client of another region gets a 301 PermanentRedirect.
+// The regions are learned from ListBuckets (BucketRegion) and GetBucketLocation,
+// a bucket never seen before is located with GetBucketLocation.
+type s3_client struct {
+	*s3.Client
+	mutex   sync.Mutex
+	regions map[string]string
+}
+
+func new_s3_client(cfg aws.Config) *s3_client {
+	return &s3_client{Client: s3.NewFromConfig(cfg), regions: make(map[string]string)}
+}
+
+func (client *s3_client) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+	output, err := client.Client.ListBuckets(ctx, params, optFns...)
+	if err == nil {
+		for _, bucket := range output.Buckets {
+			if bucket.BucketRegion != nil {
+				client.set_region(aws.ToString(bucket.Name), aws.ToString(bucket.BucketRegion))
+			}
+		}
+	}
+	return output, err
+}
+
+func (client *s3_client) GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+	output, err := client.Client.GetBucketLocation(ctx, params, optFns...)
+	if err == nil {
+		client.set_region(aws.ToString(params.Bucket), location_region(string(output.LocationConstraint)))
+	}
+	return output, err
+}
+
+// location_region converts a LocationConstraint to a region name
+func location_region(constraint string) string {
+	switch constraint {
+	case "":
+		return "us-east-1"
+	case "EU":
+		return "eu-west-1"
+	default:
+		return constraint
+	}
+}
+
+func (client *s3_client) set_region(bucket, region string) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	client.regions[bucket] = region
+}
+
+// in_region appends the region of the bucket to the options of a request,
+// the options are left untouched when the bucket can't be located
+func (client *s3_client) in_region(ctx context.Context, bucket *string, optFns []func(*s3.Options)) []func(*s3.Options) {
+	name := aws.ToString(bucket)
+	client.mutex.Lock()
+	region, ok := client.regions[name]
+	client.mutex.Unlock()
+
+	if !ok {
+		// the lookup is paced, counted and retried like the api calls of the catalog
+		err := servicemaster.Request(ctx, func(ctx context.Context) error {
+			_, err := client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{Bucket: bucket})
+			return err
+		})
+		if err != nil {
+			return optFns
+		}
+		client.mutex.Lock()
+		region = client.regions[name]
+		client.mutex.Unlock()
+	}
+	return append(optFns, func(options *s3.Options) { options.Region = region })
+}
+
+func (client *s3_client) GetBucketPolicy(ctx context.Context, params *s3.GetBucketPolicyInput, optFns ...func(*s3.Options)) (*s3.GetBucketPolicyOutput, error) {
+	return client.Client.GetBucketPolicy(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) GetBucketPolicyStatus(ctx context.Context, params *s3.GetBucketPolicyStatusInput, optFns ...func(*s3.Options)) (*s3.GetBucketPolicyStatusOutput, error) {
+	return client.Client.GetBucketPolicyStatus(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) GetBucketAcl(ctx context.Context, params *s3.GetBucketAclInput, optFns ...func(*s3.Options)) (*s3.GetBucketAclOutput, error) {
+	return client.Client.GetBucketAcl(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) GetPublicAccessBlock(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error) {
+	return client.Client.GetPublicAccessBlock(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+	return client.Client.GetBucketEncryption(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) GetBucketVersioning(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+	return client.Client.GetBucketVersioning(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) GetBucketLogging(ctx context.Context, params *s3.GetBucketLoggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketLoggingOutput, error) {
+	return client.Client.GetBucketLogging(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) GetBucketWebsite(ctx context.Context, params *s3.GetBucketWebsiteInput, optFns ...func(*s3.Options)) (*s3.GetBucketWebsiteOutput, error) {
+	return client.Client.GetBucketWebsite(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) GetBucketCors(ctx context.Context, params *s3.GetBucketCorsInput, optFns ...func(*s3.Options)) (*s3.GetBucketCorsOutput, error) {
+	return client.Client.GetBucketCors(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) GetBucketReplication(ctx context.Context, params *s3.GetBucketReplicationInput, optFns ...func(*s3.Options)) (*s3.GetBucketReplicationOutput, error) {
+	return client.Client.GetBucketReplication(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) GetBucketLifecycleConfiguration(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+	return client.Client.GetBucketLifecycleConfiguration(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) GetBucketOwnershipControls(ctx context.Context, params *s3.GetBucketOwnershipControlsInput, optFns ...func(*s3.Options)) (*s3.GetBucketOwnershipControlsOutput, error) {
+	return client.Client.GetBucketOwnershipControls(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+func (client *s3_client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return client.Client.ListObjectsV2(ctx, params, client.in_region(ctx, params.Bucket, optFns)...)
+}
+
+// SetS3MaxKeys bounds the object listing of every bucket to max_keys keys, 0 removes the object listing calls
+func (registry *Registry) SetS3MaxKeys(max_keys int) {
+	for i := range registry.Services {
+		svc := &registry.Services[i]
+		var apicalls []servicemaster.APICall
+		for _, apicall := range svc.ApiCalls {
+			if !utils.Find(apicall.Tags, S3_OBJECTS_TAG) {
+				apicalls = append(apicalls, apicall)
+				continue
+			}
+			if max_keys <= 0 {
+				continue
+			}
+			// the keys are spread evenly over the fewest pages
+			apicall.MaxPages = (max_keys + s3_page_size - 1) / s3_page_size
+			if input, ok := apicall.Input.(*s3.ListObjectsV2Input); ok {
+				input_copy := *input
+				input_copy.MaxKeys = aws.Int32(int32((max_keys + apicall.MaxPages - 1) / apicall.MaxPages))
+				apicall.Input = &input_copy
+			}
+			apicalls = append(apicalls, apicall)
+		}
+		svc.ApiCalls = apicalls
+	}
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// DEFAULT_ROLE_SESSION_NAME is used when neither the CLI nor the profile set role_session_name
+const DEFAULT_ROLE_SESSION_NAME = "aws-enumerator"
+
+// AssumeRoleOptions describes a role to assume with the loaded credentials
+type AssumeRoleOptions struct {
+	RoleARN         string
+	ExternalID      string
+	RoleSessionName string
+}
+
+// AssumeRole exchanges the credentials for temporary credentials of the role,
+// the role is assumed again by the provider of the result when they expire
+func AssumeRole(ctx context.Context, creds *AWSCredentials, options AssumeRoleOptions) (*AWSCredentials, error) {
+	region := creds.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	sts_svc := sts.New(sts.Options{
+		Region:      region,
+		Credentials: creds.credentialsProvider(),
+	})
+
+	session_name := options.RoleSessionName
+	if session_name == "" {
+		session_name = DEFAULT_ROLE_SESSION_NAME
+	}
+	provider := aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts_svc, options.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = session_name
+		if options.ExternalID != "" {
+			o.ExternalID = aws.String(options.ExternalID)
+		}
+	}))
+
+	value, err := provider.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume role %s: %v", options.RoleARN, err)
+	}
+
+	return &AWSCredentials{
+		AccessKeyID:     value.AccessKeyID,
+		SecretAccessKey: value.SecretAccessKey,
+		SessionToken:    value.SessionToken,
+		Region:          creds.Region,
+		Source:          "assume_role",
+		RoleARN:         options.RoleARN,
+		Provider:        provider,
+	}, nil
+}
+
+// AssumeRoleChain assumes the roles one after another, every hop uses the credentials of the previous one.
+// The external id is only sent for the last role of the chain.
+func AssumeRoleChain(ctx context.Context, creds *AWSCredentials, roleARNs []string, externalID, sessionName string) (*AWSCredentials, error) {
+	for i, roleARN := range roleARNs {
+		options := AssumeRoleOptions{RoleARN: roleARN, RoleSessionName: sessionName}
+		if i == len(roleARNs)-1 {
+			options.ExternalID = externalID
+		}
+
+		assumed, err := AssumeRole(ctx, creds, options)
+		if err != nil {
+			return nil, err
+		}
+		creds = assumed
+	}
+	return creds, nil
+}
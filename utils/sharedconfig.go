@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// Source types of a profile of the shared config files
+const (
+	PROFILE_STATIC             = "static"
+	PROFILE_ASSUME_ROLE        = "assume_role"
+	PROFILE_CREDENTIAL_PROCESS = "credential_process"
+	PROFILE_SSO                = "sso"
+	PROFILE_WEB_IDENTITY       = "web_identity"
+	PROFILE_UNKNOWN            = "unknown"
+)
+
+// ProfileInfo describes a profile of ~/.aws/config and ~/.aws/credentials
+type ProfileInfo struct {
+	Name   string
+	Source string // one of the PROFILE_* source types
+	Region string
+}
+
+// sharedConfigFile returns the config file, honouring AWS_CONFIG_FILE
+func sharedConfigFile() string {
+	if file := os.Getenv("AWS_CONFIG_FILE"); file != "" {
+		return file
+	}
+	return config.DefaultSharedConfigFilename()
+}
+
+// sharedCredentialsFile returns the credentials file, honouring AWS_SHARED_CREDENTIALS_FILE
+func sharedCredentialsFile() string {
+	if file := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); file != "" {
+		return file
+	}
+	return config.DefaultSharedCredentialsFilename()
+}
+
+// loadFromSharedConfig resolves the credentials of a profile with the rules of the AWS CLI:
+// static keys, role_arn / source_profile chains, credential_process and sso_* with the token cached by `aws sso login`
+func loadFromSharedConfig(ctx context.Context, profileName string) (*AWSCredentials, error) {
+	settings := profileSettings(profileName)
+	if len(settings) == 0 {
+		return nil, fmt.Errorf("profile %s not found in %s or %s", profileName, sharedCredentialsFile(), sharedConfigFile())
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithSharedConfigProfile(profileName))
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		if profileSource(settings) == PROFILE_SSO {
+			return nil, fmt.Errorf("%v (run `aws sso login --profile %s` to refresh the cached SSO token)", err, profileName)
+		}
+		return nil, err
+	}
+
+	creds := &AWSCredentials{
+		AccessKeyID:     value.AccessKeyID,
+		SecretAccessKey: value.SecretAccessKey,
+		SessionToken:    value.SessionToken,
+		Region:          cfg.Region,
+		Source:          profileSource(settings),
+		Profile:         profileName,
+		RoleARN:         settings["role_arn"],
+		Provider:        cachedProvider(cfg.Credentials),
+	}
+	if creds.Source == PROFILE_STATIC {
+		creds.Source = "profile"
+	}
+	return creds, nil
+}
+
+// NewAWSConfig returns the SDK config of the resolved credentials, the process environment is never modified.
+// Settings other than the credentials (endpoints, timeouts...) are still read from the profile of the credentials.
+// The SDK clients send every request once, throttled and transient errors are retried by servicemaster (MAX_ATTEMPTS).
+func NewAWSConfig(ctx context.Context, creds *AWSCredentials) (aws.Config, error) {
+	options := []func(*config.LoadOptions) error{
+		config.WithCredentialsProvider(creds.credentialsProvider()),
+		config.WithRetryMaxAttempts(1),
+	}
+	if creds.Region != "" {
+		options = append(options, config.WithRegion(creds.Region))
+	}
+	if creds.Profile != "" {
+		options = append(options, config.WithSharedConfigProfile(creds.Profile))
+	}
+	return config.LoadDefaultConfig(ctx, options...)
+}
+
+// credentialsProvider returns the refreshing provider of the credentials, SSO tokens and assumed roles
+// outlive a long enumeration this way; the static keys of the environment and the .env file never expire
+func (creds *AWSCredentials) credentialsProvider() aws.CredentialsProvider {
+	if creds.Provider != nil {
+		return creds.Provider
+	}
+	return credentials.NewStaticCredentialsProvider(creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken)
+}
+
+// cachedProvider wraps the provider in a credentials cache unless it is one already
+func cachedProvider(provider aws.CredentialsProvider) aws.CredentialsProvider {
+	if _, ok := provider.(*aws.CredentialsCache); ok {
+		return provider
+	}
+	return aws.NewCredentialsCache(provider)
+}
+
+// profileSource returns the source type of a profile from its settings
+func profileSource(settings map[string]string) string {
+	switch {
+	case settings["role_arn"] != "" && settings["web_identity_token_file"] != "":
+		return PROFILE_WEB_IDENTITY
+	case settings["role_arn"] != "":
+		return PROFILE_ASSUME_ROLE
+	case settings["sso_session"] != "" || settings["sso_start_url"] != "":
+		return PROFILE_SSO
+	case settings["credential_process"] != "":
+		return PROFILE_CREDENTIAL_PROCESS
+	case settings["aws_access_key_id"] != "":
+		return PROFILE_STATIC
+	default:
+		return PROFILE_UNKNOWN
+	}
+}
+
+// ListProfiles lists the profiles of the shared credentials and config files with their source type and region
+func ListProfiles() ([]ProfileInfo, error) {
+	names := make(map[string]bool)
+	for _, section := range readINISections(sharedCredentialsFile()) {
+		names[section] = true
+	}
+	for _, section := range readINISections(sharedConfigFile()) {
+		switch {
+		case section == "default":
+			names[section] = true
+		case strings.HasPrefix(section, "profile "):
+			names[strings.TrimSpace(strings.TrimPrefix(section, "profile "))] = true
+		}
+	}
+
+	var profiles []ProfileInfo
+	for name := range names {
+		settings := profileSettings(name)
+		profiles = append(profiles, ProfileInfo{
+			Name:   name,
+			Source: profileSource(settings),
+			Region: settings["region"],
+		})
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// hasProfile reports whether the profile exists in one of the shared files
+func hasProfile(profileName string) bool {
+	return len(profileSettings(profileName)) > 0
+}
+
+// profileSettings merges the settings of a profile from the config and the credentials file,
+// the credentials file wins like in the AWS CLI
+func profileSettings(profileName string) map[string]string {
+	settings := readINISection(sharedConfigFile(), "profile "+profileName)
+	if profileName == "default" {
+		for key, value := range readINISection(sharedConfigFile(), "default") {
+			settings[key] = value
+		}
+	}
+	for key, value := range readINISection(sharedCredentialsFile(), profileName) {
+		settings[key] = value
+	}
+	return settings
+}
+
+// readINISections returns the section names of an AWS ini file
+func readINISections(filename string) []string {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var sections []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sections = append(sections, strings.TrimSpace(strings.Trim(line, "[]")))
+		}
+	}
+	return sections
+}
+
+// readINISection returns the key/value pairs of a section of an AWS ini file
+func readINISection(filename, section string) map[string]string {
+	settings := make(map[string]string)
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return settings
+	}
+	defer file.Close()
+
+	var currentSection string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			currentSection = strings.TrimSpace(strings.Trim(line, "[]"))
+			continue
+		}
+
+		if currentSection != section {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[1]) != "" {
+			settings[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return settings
+}
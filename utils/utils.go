@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -9,18 +10,106 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 )
 
-// Global variables for file paths
+// Global variables for file paths, pointed at the directory of the run by SetOutputDir
 var (
 	FILEPATH       = "enum-results/"
 	ERROR_FILEPATH = "enum-results/errors/"
 )
 
+// VERSION of the tool, recorded in the manifest of every run ( -ldflags "-X github.com/threatroute66/aws-enumerator/utils.VERSION=..." )
+var VERSION = "2.0.0"
+
+// RESULTS_ROOT holds a directory per run, named by the start time of the run (RUN_LAYOUT)
+const (
+	RESULTS_ROOT = "enum-results"
+	RUN_LAYOUT   = "20060102-150405"
+)
+
+// SetOutputDir points FILEPATH and ERROR_FILEPATH at the directory of a run
+func SetOutputDir(dir string) {
+	FILEPATH = filepath.Clean(dir) + string(filepath.Separator)
+	ERROR_FILEPATH = filepath.Join(dir, "errors") + string(filepath.Separator)
+}
+
+// RunDirectory creates and returns the default directory of a run started at the given time,
+// runs started in the same second get a "-2", "-3"... suffix (see runOrder)
+func RunDirectory(start time.Time) string {
+	name := filepath.Join(RESULTS_ROOT, start.Format(RUN_LAYOUT))
+	os.MkdirAll(RESULTS_ROOT, 0755)
+	dir := name
+	// Mkdir fails on an existing directory, two runs can't both claim the same one
+	for n := 2; ; n++ {
+		if err := os.Mkdir(dir, 0755); !os.IsExist(err) {
+			return dir
+		}
+		dir = fmt.Sprintf("%s-%d", name, n)
+	}
+}
+
+// runOrder parses the name of a run directory into its start time and its suffix (1 without suffix)
+func runOrder(name string) (time.Time, int, bool) {
+	if len(name) < len(RUN_LAYOUT) {
+		return time.Time{}, 0, false
+	}
+	start, err := time.Parse(RUN_LAYOUT, name[:len(RUN_LAYOUT)])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	suffix := name[len(RUN_LAYOUT):]
+	if suffix == "" {
+		return start, 1, true
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(suffix, "-"))
+	if !strings.HasPrefix(suffix, "-") || err != nil || n < 2 {
+		return time.Time{}, 0, false
+	}
+	return start, n, true
+}
+
+// LatestRun returns the directory of the most recent run under RESULTS_ROOT,
+// or RESULTS_ROOT itself when it holds no run directory (results of the previous versions)
+func LatestRun() string {
+	folders, err := ioutil.ReadDir(RESULTS_ROOT)
+	if err != nil {
+		return RESULTS_ROOT
+	}
+	latest, latest_start, latest_n := "", time.Time{}, 0
+	for _, folder := range folders {
+		start, n, ok := runOrder(folder.Name())
+		if !ok || !folder.IsDir() {
+			continue
+		}
+		if latest == "" || start.After(latest_start) || (start.Equal(latest_start) && n > latest_n) {
+			latest, latest_start, latest_n = folder.Name(), start, n
+		}
+	}
+	if latest == "" {
+		return RESULTS_ROOT
+	}
+	return filepath.Join(RESULTS_ROOT, latest)
+}
+
+// GLOBAL_REGION is the result folder of services that are not bound to a region
+const GLOBAL_REGION = "global"
+
+// ResultPath returns the result folder of a region
+func ResultPath(region string) string {
+	return filepath.Join(FILEPATH, region) + string(filepath.Separator)
+}
+
+// ErrorPath returns the error folder of a region
+func ErrorPath(region string) string {
+	return filepath.Join(FILEPATH, region, "errors") + string(filepath.Separator)
+}
+
 // Color functions for terminal output
 func Red(text interface{}) string {
 	return fmt.Sprintf("\033[31m%v\033[0m", text)
@@ -44,7 +133,13 @@ type AWSCredentials struct {
 	SecretAccessKey string
 	SessionToken    string
 	Region          string
-	Source          string // "profile", "env", or "env_file"
+	Source          string // "profile", "env", "env_file", "assume_role", "credential_process", "sso" or "web_identity"
+	Profile         string // profile of the shared config files the credentials were resolved from
+	RoleARN         string // role of the credentials when Source is "assume_role"
+
+	// Provider refreshes the credentials of profiles and assumed roles, the keys above only hold its first value.
+	// It is nil for the static keys of the environment and the .env file.
+	Provider awsv2.CredentialsProvider
 }
 
 // PackResponse packs response data for JSON output (returns string for servicemaster)
@@ -80,51 +175,41 @@ func CreateAWScredentialsFile(region, accessKeyID, secretAccessKey, sessionToken
 	}
 }
 
-// LoadCredentials loads AWS credentials with profile support
-func LoadCredentials(profile string) (*AWSCredentials, error) {
-	// Priority order:
-	// 1. AWS profile from ~/.aws/credentials (if profile specified)
-	// 2. Environment variables
+// LoadCredentials loads AWS credentials with profile support, ctx cancels the resolution of profiles
+func LoadCredentials(ctx context.Context, profile string) (*AWSCredentials, error) {
+	// Priority order (same as the AWS CLI, plus the .env file of the cred command):
+	// 1. Profile of the shared config files (if profile specified)
+	// 2. Environment variables (AWS_ACCESS_KEY_ID..., then AWS_PROFILE)
 	// 3. .env file (backward compatibility)
+	// 4. default profile of the shared config files
 
-	// If profile is specified, load from AWS credentials file
+	// If profile is specified, load from the shared config files
 	if profile != "" {
-		return loadFromProfile(profile)
+		return loadFromProfile(ctx, profile)
 	}
 
 	// Check environment variables first
 	if creds := loadFromEnvironment(); creds != nil {
 		return creds, nil
 	}
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		return loadFromProfile(ctx, profile)
+	}
 
 	// Fall back to .env file for backward compatibility
-	return loadFromEnvFile()
+	creds, err := loadFromEnvFile()
+	if err != nil && hasProfile("default") {
+		return loadFromProfile(ctx, "default")
+	}
+	return creds, err
 }
 
 // loadFromProfile loads credentials from AWS profile
-func loadFromProfile(profileName string) (*AWSCredentials, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
-
-	credFile := filepath.Join(homeDir, ".aws", "credentials")
-	configFile := filepath.Join(homeDir, ".aws", "config")
-
-	// Load credentials from profile
-	creds, err := parseAWSCredentialsFile(credFile, profileName)
+func loadFromProfile(ctx context.Context, profileName string) (*AWSCredentials, error) {
+	creds, err := loadFromSharedConfig(ctx, profileName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load profile %s: %v", profileName, err)
 	}
-
-	// Load region from config file if not in credentials
-	if creds.Region == "" {
-		if region := getRegionFromConfig(configFile, profileName); region != "" {
-			creds.Region = region
-		}
-	}
-
-	creds.Source = "profile"
 	return creds, nil
 }
 
@@ -192,104 +277,6 @@ func loadFromEnvFile() (*AWSCredentials, error) {
 	return creds, nil
 }
 
-// parseAWSCredentialsFile parses AWS credentials file
-func parseAWSCredentialsFile(filename, profileName string) (*AWSCredentials, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var currentProfile string
-	creds := &AWSCredentials{}
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Check for profile section
-		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			currentProfile = strings.Trim(line, "[]")
-			continue
-		}
-
-		// Skip if not in target profile
-		if currentProfile != profileName {
-			continue
-		}
-
-		// Parse key-value pairs
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		switch key {
-		case "aws_access_key_id":
-			creds.AccessKeyID = value
-		case "aws_secret_access_key":
-			creds.SecretAccessKey = value
-		case "aws_session_token":
-			creds.SessionToken = value
-		case "region":
-			creds.Region = value
-		}
-	}
-
-	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
-		return nil, fmt.Errorf("profile %s not found or incomplete", profileName)
-	}
-
-	return creds, nil
-}
-
-// getRegionFromConfig gets region from AWS config file
-func getRegionFromConfig(filename, profileName string) string {
-	file, err := os.Open(filename)
-	if err != nil {
-		return ""
-	}
-	defer file.Close()
-
-	var currentProfile string
-	scanner := bufio.NewScanner(file)
-
-	// For default profile, look for [default], for others look for [profile name]
-	targetSection := "default"
-	if profileName != "default" {
-		targetSection = "profile " + profileName
-	}
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			currentProfile = strings.Trim(line, "[]")
-			continue
-		}
-
-		if currentProfile != targetSection {
-			continue
-		}
-
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 && strings.TrimSpace(parts[0]) == "region" {
-			return strings.TrimSpace(parts[1])
-		}
-	}
-
-	return ""
-}
-
 // CreateAWSSession creates an AWS session with the loaded credentials
 func CreateAWSSession(creds *AWSCredentials) (*session.Session, error) {
 	config := &aws.Config{}
@@ -309,34 +296,6 @@ func CreateAWSSession(creds *AWSCredentials) (*session.Session, error) {
 	return session.NewSession(config)
 }
 
-// ListAvailableProfiles lists available AWS profiles
-func ListAvailableProfiles() ([]string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, err
-	}
-
-	credFile := filepath.Join(homeDir, ".aws", "credentials")
-	file, err := os.Open(credFile)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var profiles []string
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			profile := strings.Trim(line, "[]")
-			profiles = append(profiles, profile)
-		}
-	}
-
-	return profiles, nil
-}
-
 // EnsureDirectories creates necessary directories for output files
 func EnsureDirectories() error {
 	// Create main results directory
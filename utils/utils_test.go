@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunDirectory(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if LatestRun() != RESULTS_ROOT {
+		t.Errorf("latest run without results: %s", LatestRun())
+	}
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var dirs []string
+	for i := 0; i < 11; i++ {
+		dirs = append(dirs, RunDirectory(start))
+	}
+	if dirs[0] != filepath.Join(RESULTS_ROOT, "20240501-120000") || dirs[1] != dirs[0]+"-2" || dirs[10] != dirs[0]+"-11" {
+		t.Errorf("run directories %v", dirs)
+	}
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Errorf("%s not created: %v", dir, err)
+		}
+	}
+	// -11 is newer than -2 even though it sorts before it
+	if latest := LatestRun(); latest != dirs[10] {
+		t.Errorf("latest run %s, want %s", latest, dirs[10])
+	}
+
+	earlier := RunDirectory(start.Add(-time.Second))
+	later := RunDirectory(start.Add(time.Second))
+	os.Mkdir(filepath.Join(RESULTS_ROOT, "20240501-120001-x"), 0755)
+	os.Mkdir(filepath.Join(RESULTS_ROOT, "20240501-120001-1"), 0755)
+	if latest := LatestRun(); latest != later || earlier == later {
+		t.Errorf("latest run %s, want %s", latest, later)
+	}
+}